@@ -0,0 +1,269 @@
+package ffmpegtree
+
+import (
+	"fmt"
+)
+
+// EqFilter adjusts brightness, contrast, saturation and gamma. Nil values are left unchanged.
+type EqFilter struct {
+	TimelineAcceptingFilterNode
+
+	// Brightness is between -1 and 1, 0 by default.
+	Brightness *float64
+
+	// Contrast is between -1000 and 1000, 1 by default.
+	Contrast *float64
+
+	// Saturation is between 0 and 3, 1 by default.
+	Saturation *float64
+
+	// Gamma is between 0.1 and 10, 1 by default.
+	Gamma *float64
+}
+
+func (f *EqFilter) FilterString() string {
+	return filterWithOptions("eq", floatOptions([]floatOption{
+		{"brightness", f.Brightness}, {"contrast", f.Contrast}, {"saturation", f.Saturation}, {"gamma", f.Gamma},
+	}))
+}
+
+func NewEqFilter(input INode) *EqFilter {
+	return &EqFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+	}
+}
+
+// HueFilter rotates hue and changes saturation and brightness. Nil values are left unchanged.
+type HueFilter struct {
+	TimelineAcceptingFilterNode
+
+	// Hue is the rotation in degrees.
+	Hue *float64
+
+	// Saturation is between -10 and 10, 1 by default.
+	Saturation *float64
+
+	// Brightness is between -10 and 10, 0 by default.
+	Brightness *float64
+}
+
+func (f *HueFilter) FilterString() string {
+	return filterWithOptions("hue", floatOptions([]floatOption{{"h", f.Hue}, {"s", f.Saturation}, {"b", f.Brightness}}))
+}
+
+func NewHueFilter(input INode) *HueFilter {
+	return &HueFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+	}
+}
+
+// RGB is an adjustment of red, green and blue components, each between -1 and 1.
+type RGB struct {
+	R, G, B float64
+}
+
+// ColorBalanceFilter shifts colors of shadows, midtones and highlights.
+type ColorBalanceFilter struct {
+	TimelineAcceptingFilterNode
+	Shadows, Midtones, Highlights RGB
+
+	// PreserveLightness keeps lightness of pixels while changing their colors.
+	PreserveLightness bool
+}
+
+func (f *ColorBalanceFilter) FilterString() string {
+	opts := make([]string, 0)
+	for _, r := range []struct {
+		suffix string
+		rgb    RGB
+	}{{"s", f.Shadows}, {"m", f.Midtones}, {"h", f.Highlights}} {
+		for _, c := range []struct {
+			prefix string
+			v      float64
+		}{{"r", r.rgb.R}, {"g", r.rgb.G}, {"b", r.rgb.B}} {
+			if c.v != 0 {
+				opts = append(opts, fmt.Sprintf("%v%v=%v", c.prefix, r.suffix, fmtFloat(c.v)))
+			}
+		}
+	}
+	if f.PreserveLightness {
+		opts = append(opts, "pl=1")
+	}
+
+	return filterWithOptions("colorbalance", opts)
+}
+
+func NewColorBalanceFilter(input INode, shadows, midtones, highlights RGB) *ColorBalanceFilter {
+	return &ColorBalanceFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		Shadows:                     shadows,
+		Midtones:                    midtones,
+		Highlights:                  highlights,
+	}
+}
+
+// ColorChannelMixerFilter recomputes every color component as a weighted sum of input components. Matrix rows are
+// output red, green and blue, and columns are weights of input red, green and blue.
+type ColorChannelMixerFilter struct {
+	TimelineAcceptingFilterNode
+	Matrix [3][3]float64
+}
+
+func (f *ColorChannelMixerFilter) FilterString() string {
+	opts := make([]string, 0, 9)
+	components := "rgb"
+	for i, row := range f.Matrix {
+		for j, v := range row {
+			opts = append(opts, fmt.Sprintf("%c%c=%v", components[i], components[j], fmtFloat(v)))
+		}
+	}
+
+	return filterWithOptions("colorchannelmixer", opts)
+}
+
+func NewColorChannelMixerFilter(input INode, matrix [3][3]float64) *ColorChannelMixerFilter {
+	return &ColorChannelMixerFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		Matrix:                      matrix,
+	}
+}
+
+// SepiaMatrix is the matrix of ColorChannelMixerFilter giving a sepia tone.
+var SepiaMatrix = [3][3]float64{
+	{0.393, 0.769, 0.189},
+	{0.349, 0.686, 0.168},
+	{0.272, 0.534, 0.131},
+}
+
+// Lut3DFilter applies a 3D lookup table from a file such as a '.cube' file.
+type Lut3DFilter struct {
+	TimelineAcceptingFilterNode
+	Path string
+
+	interpolation string
+}
+
+func (f *Lut3DFilter) FilterString() string {
+	res := "lut3d=file=" + escapeFilterArg(f.Path)
+	if f.interpolation != "" {
+		res += ":interp=" + f.interpolation
+	}
+
+	return res
+}
+
+// NewLut3DFilter applies the lookup table at path to input. interpolation is one of 'nearest', 'trilinear',
+// 'tetrahedral', 'pyramid' and 'prism'. ffmpeg's default is used if it is empty.
+func NewLut3DFilter(input INode, path, interpolation string) (*Lut3DFilter, error) {
+	switch interpolation {
+	case "", "nearest", "trilinear", "tetrahedral", "pyramid", "prism":
+	default:
+		return nil, fmt.Errorf("unknown lut3d interpolation: %v", interpolation)
+	}
+
+	return &Lut3DFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		Path:                        path,
+		interpolation:               interpolation,
+	}, nil
+}
+
+// ColorspaceFilter converts colorspace, transfer characteristics, primaries and range, such as to 'bt709'. Empty values
+// are either taken from All or left unchanged.
+type ColorspaceFilter struct {
+	TimelineAcceptingFilterNode
+
+	// All sets space, transfer characteristics and primaries at once, such as 'bt709' or 'bt601-6-625'.
+	All string
+
+	Space, Trc, Primaries string
+
+	// Range is either 'tv' or 'pc'.
+	Range string
+
+	// Input overrides properties of the input, such as 'bt601-6-625', if they are missing or wrong in the input.
+	Input string
+}
+
+func (f *ColorspaceFilter) FilterString() string {
+	opts := make([]string, 0)
+	for _, o := range [][2]string{
+		{"all", f.All}, {"space", f.Space}, {"trc", f.Trc}, {"primaries", f.Primaries}, {"range", f.Range}, {"iall", f.Input},
+	} {
+		if o[1] != "" {
+			opts = append(opts, o[0]+"="+o[1])
+		}
+	}
+
+	return filterWithOptions("colorspace", opts)
+}
+
+// NewColorspaceFilter converts input to all, such as 'bt709'.
+func NewColorspaceFilter(input INode, all string) *ColorspaceFilter {
+	return &ColorspaceFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		All:                         all,
+	}
+}
+
+func (f *EqFilter) PropagateInfo(inputs []StreamInfo) StreamInfo                { return inputs[0] }
+func (f *HueFilter) PropagateInfo(inputs []StreamInfo) StreamInfo               { return inputs[0] }
+func (f *ColorBalanceFilter) PropagateInfo(inputs []StreamInfo) StreamInfo      { return inputs[0] }
+func (f *ColorChannelMixerFilter) PropagateInfo(inputs []StreamInfo) StreamInfo { return inputs[0] }
+func (f *Lut3DFilter) PropagateInfo(inputs []StreamInfo) StreamInfo             { return inputs[0] }
+func (f *ColorspaceFilter) PropagateInfo(inputs []StreamInfo) StreamInfo        { return inputs[0] }
+
+type floatOption struct {
+	key   string
+	value *float64
+}
+
+// floatOptions returns options in the form of 'key=value' for the ones which are set.
+func floatOptions(opts []floatOption) []string {
+	res := make([]string, 0, len(opts))
+	for _, o := range opts {
+		if o.value != nil {
+			res = append(res, fmt.Sprintf("%v=%v", o.key, fmtFloat(*o.value)))
+		}
+	}
+
+	return res
+}
+
+// ZscaleFilter converts transfer characteristics, matrix, primaries and range with the zimg library, such as to
+// 'bt709'. Empty values are left unchanged. Unlike colorspace, it cannot be enabled for a part of the timeline since it
+// may change the pixel format.
+type ZscaleFilter struct {
+	BaseFilterNode
+	Transfer, Matrix, Primaries string
+
+	// Range is either 'limited' or 'full'.
+	Range string
+
+	// Input values override properties of the input, if they are missing or wrong in the input.
+	InputTransfer, InputMatrix, InputPrimaries, InputRange string
+}
+
+func (f *ZscaleFilter) FilterString() string {
+	opts := make([]string, 0)
+	for _, o := range [][2]string{
+		{"t", f.Transfer}, {"m", f.Matrix}, {"p", f.Primaries}, {"r", f.Range},
+		{"tin", f.InputTransfer}, {"min", f.InputMatrix}, {"pin", f.InputPrimaries}, {"rin", f.InputRange},
+	} {
+		if o[1] != "" {
+			opts = append(opts, o[0]+"="+o[1])
+		}
+	}
+
+	return filterWithOptions("zscale", opts)
+}
+
+// NewZscaleFilter converts input to transfer, matrix and primaries, such as 'bt709' for all of them.
+func NewZscaleFilter(input INode, transfer, matrix, primaries string) *ZscaleFilter {
+	return &ZscaleFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		Transfer:       transfer,
+		Matrix:         matrix,
+		Primaries:      primaries,
+	}
+}
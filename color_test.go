@@ -0,0 +1,57 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorFilters(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+
+	eq := NewEqFilter(i)
+	eq.Brightness, eq.Saturation = float64Ptr(0.06), float64Ptr(1.5)
+	require.Equal(t, "eq=brightness=0.06:saturation=1.5", eq.FilterString())
+
+	hue := NewHueFilter(i)
+	hue.Hue = float64Ptr(-30)
+	hue.Window(time.Second, 2*time.Second)
+	require.Equal(t, "hue=h=-30:enable='between(t,1,2)'", FilterNodeToStr(hue))
+
+	cb := NewColorBalanceFilter(i, RGB{B: 0.2}, RGB{}, RGB{R: 0.1, G: -0.05})
+	cb.PreserveLightness = true
+	require.Equal(t, "colorbalance=bs=0.2:rh=0.1:gh=-0.05:pl=1", cb.FilterString())
+
+	require.Equal(t,
+		"colorchannelmixer=rr=0.393:rg=0.769:rb=0.189:gr=0.349:gg=0.686:gb=0.168:br=0.272:bg=0.534:bb=0.131",
+		NewColorChannelMixerFilter(i, SepiaMatrix).FilterString())
+
+	lut, err := NewLut3DFilter(i, "C:/luts/teal & orange's.cube", "tetrahedral")
+	require.NoError(t, err)
+	require.Equal(t, `lut3d=file=C\\:/luts/teal & orange\\\'s.cube:interp=tetrahedral`, lut.FilterString())
+	_, err = NewLut3DFilter(i, "teal.cube", "tetrahedral:file=other.cube")
+	require.Error(t, err)
+
+	cs := NewColorspaceFilter(i, "bt709")
+	cs.Range, cs.Input = "tv", "bt601-6-625"
+	require.Equal(t, "colorspace=all=bt709:range=tv:iall=bt601-6-625", cs.FilterString())
+
+	zs := NewZscaleFilter(i, "bt709", "bt709", "bt709")
+	zs.Range, zs.InputTransfer = "limited", "smpte2084"
+	require.Equal(t, "zscale=t=bt709:m=bt709:p=bt709:r=limited:tin=smpte2084", zs.FilterString())
+}
+
+func TestCustomCurves(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+
+	c := NewCustomCurvesFilter(i, CurvePoint{0, 0}, CurvePoint{0.5, 0.58}, CurvePoint{1, 1})
+	c.Blue = []CurvePoint{{0, 0.1}, {1, 0.9}}
+	require.Equal(t, "curves=master='0/0 0.5/0.58 1/1':blue='0/0.1 1/0.9'", c.FilterString())
+
+	c.Since(3)
+	args := Select([]INode{c}, "out.mp4", nil)
+	require.Equal(t, "[0:0]curves=master='0/0 0.5/0.58 1/1':blue='0/0.1 1/0.9':enable='gte(t,3)'", args.FilterComplex())
+
+	require.Equal(t, "curves=preset=vintage", NewCurvesFilter(i, "vintage").FilterString())
+}
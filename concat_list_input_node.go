@@ -0,0 +1,123 @@
+package ffmpegtree
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConcatListInput implements IInputNode
+var _ IInputNode = &ConcatListInput{}
+
+// ConcatEntry is a file in a concat list. Inpoint, Outpoint and Duration are optional.
+type ConcatEntry struct {
+	Path                        string
+	Inpoint, Outpoint, Duration *time.Duration
+}
+
+// ConcatListInput joins files with the concat demuxer, which does not re-encode, hence files should have the same
+// codecs. Relative paths are resolved relative to the list file.
+type ConcatListInput struct {
+	BaseNode
+	ListPath string
+	Entries  []ConcatEntry
+	inputIdx int
+}
+
+func (c *ConcatListInput) ToString() []string {
+	return []string{"-f", "concat", "-safe", "0", "-i", c.ListPath}
+}
+
+func (c *ConcatListInput) GetInputIdx() int {
+	return c.inputIdx
+}
+
+func (c *ConcatListInput) SetInputIdx(idx int) {
+	c.inputIdx = idx
+}
+
+func (c *ConcatListInput) GetInputName() string {
+	return c.ListPath
+}
+
+// StreamInfo only knows the duration, if every entry is cut or has a duration.
+func (c *ConcatListInput) StreamInfo(string) StreamInfo {
+	var total time.Duration
+	for _, e := range c.Entries {
+		switch {
+		case e.Duration != nil:
+			total += *e.Duration
+		case e.Outpoint != nil:
+			total += *e.Outpoint
+			if e.Inpoint != nil {
+				total -= *e.Inpoint
+			}
+		default:
+			return StreamInfo{}
+		}
+	}
+
+	return StreamInfo{Duration: &total}
+}
+
+// List returns the content of the list file in ffconcat format.
+func (c *ConcatListInput) List() string {
+	b := &bytes.Buffer{}
+	b.WriteString("ffconcat version 1.0\n")
+	for _, e := range c.Entries {
+		fmt.Fprintf(b, "file %v\n", escapeConcatPath(e.Path))
+		if e.Inpoint != nil {
+			fmt.Fprintf(b, "inpoint %v\n", fmtSeconds(*e.Inpoint))
+		}
+		if e.Outpoint != nil {
+			fmt.Fprintf(b, "outpoint %v\n", fmtSeconds(*e.Outpoint))
+		}
+		if e.Duration != nil {
+			fmt.Fprintf(b, "duration %v\n", fmtSeconds(*e.Duration))
+		}
+	}
+
+	return b.String()
+}
+
+// escapeConcatPath quotes path for the concat demuxer, in which a quote is written by closing the quoted string,
+// adding an escaped quote and opening it again.
+func escapeConcatPath(path string) string {
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// NewConcatListInput writes the list file of entries to listPath and returns an input reading it. If listPath is empty
+// list is written to a temporary file, which should be removed by the caller after ffmpeg is run.
+func NewConcatListInput(listPath string, entries ...ConcatEntry) (*ConcatListInput, error) {
+	c := &ConcatListInput{
+		BaseNode: NewBaseNode(nil),
+		ListPath: listPath,
+		Entries:  entries,
+	}
+
+	if listPath == "" {
+		f, err := os.CreateTemp("", "ffconcat-*.txt")
+		if err != nil {
+			return nil, fmt.Errorf("create concat list: %w", err)
+		}
+		c.ListPath = f.Name()
+		_, err = f.WriteString(c.List())
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(c.ListPath)
+			return nil, fmt.Errorf("write concat list: %w", err)
+		}
+
+		return c, nil
+	}
+
+	if err := os.WriteFile(listPath, []byte(c.List()), 0644); err != nil {
+		return nil, fmt.Errorf("write concat list: %w", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,35 @@
+package ffmpegtree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcatListInput(t *testing.T) {
+	in, out, d := time.Second, 3500*time.Millisecond, 4*time.Second
+	listPath := filepath.Join(t.TempDir(), "list.txt")
+	c, err := NewConcatListInput(listPath,
+		ConcatEntry{Path: "/clips/intro.mp4", Inpoint: &in, Outpoint: &out},
+		ConcatEntry{Path: "/clips/it's me.mp4", Duration: &d},
+	)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(listPath)
+	require.NoError(t, err)
+	require.Equal(t, `ffconcat version 1.0
+file '/clips/intro.mp4'
+inpoint 1
+outpoint 3.5
+file '/clips/it'\''s me.mp4'
+duration 4
+`, string(content))
+	require.Equal(t, 6500*time.Millisecond, *Info(c).Duration)
+
+	s := NewScaleFilterNode(c, 100, 100, false)
+	cmd := Select([]INode{s}, "out.mp4", nil, NewMap(c, "a"))
+	require.Equal(t, []string{"-f", "concat", "-safe", "0", "-i", listPath, "-filter_complex", "[0:0]scale=100:100", "-map", "0:a"}, []string(cmd[:10]))
+}
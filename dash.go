@@ -0,0 +1,147 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DASHOutput implements IOutput
+var _ IOutput = &DASHOutput{}
+
+// DASHOutput writes streams as an MPEG-DASH manifest with '-f dash'.
+type DASHOutput struct {
+	ManifestName    string
+	SegmentDuration time.Duration
+
+	UseTemplate, UseTimeline bool
+
+	// InitSegmentName and MediaSegmentName are segment templates such as 'init-$RepresentationID$.m4s'. They are
+	// left to ffmpeg's defaults if empty.
+	InitSegmentName, MediaSegmentName string
+
+	// AdaptationSets groups output streams such as 'id=0,streams=v id=1,streams=a'.
+	AdaptationSets string
+}
+
+func (d *DASHOutput) ToString() []string {
+	res := []string{"-f", "dash"}
+	if d.SegmentDuration > 0 {
+		res = append(res, "-seg_duration", fmtSeconds(d.SegmentDuration))
+	}
+	res = append(res, "-use_template", boolFlag(d.UseTemplate), "-use_timeline", boolFlag(d.UseTimeline))
+	if d.InitSegmentName != "" {
+		res = append(res, "-init_seg_name", d.InitSegmentName)
+	}
+	if d.MediaSegmentName != "" {
+		res = append(res, "-media_seg_name", d.MediaSegmentName)
+	}
+	if d.AdaptationSets != "" {
+		res = append(res, "-adaptation_sets", d.AdaptationSets)
+	}
+
+	return append(res, d.ManifestName)
+}
+
+// DASHStream is a stream of the compiled graph which is written as a representation of the manifest.
+type DASHStream struct {
+	Node     INode
+	Audio    bool
+	Encoding EncodingOptions
+}
+
+// dashCodecs are encoders whose output can be packaged in fragmented mp4 segments.
+var dashCodecs = map[string]map[string]bool{
+	"v": {"libx264": true, "h264": true, "libx265": true, "hevc": true, "libvpx-vp9": true, "libaom-av1": true, "libsvtav1": true},
+	"a": {"aac": true, "libfdk_aac": true, "libopus": true, "ac3": true, "eac3": true},
+}
+
+// NewDASHExecutor returns an executor which writes streams with out and output nodes which should be passed to its
+// ToFfmpeg. Video streams are put in one adaptation set and audio streams in another. Every stream should be encoded
+// with a codec which DASH supports and keyframes of video streams should be aligned with segment boundaries.
+func NewDASHExecutor(out DASHOutput, streams ...DASHStream) (*FFmpegExecutor, []INode, error) {
+	if len(streams) == 0 {
+		return nil, nil, fmt.Errorf("dash output needs at least one stream")
+	}
+	if out.SegmentDuration <= 0 {
+		return nil, nil, fmt.Errorf("segment duration should be positive")
+	}
+
+	nodes := make([]INode, 0, len(streams))
+	maps := make([]IMap, 0, len(streams))
+	opts := make([]string, 0)
+	counts := map[string]int{}
+	for i, s := range streams {
+		streamType := "v"
+		if s.Audio {
+			streamType = "a"
+		}
+		if err := validateDASHStream(s, streamType, out.SegmentDuration); err != nil {
+			return nil, nil, fmt.Errorf("stream %v: %w", i, err)
+		}
+
+		if _, ok := s.Node.(IFilterNode); ok {
+			nodes = append(nodes, s.Node)
+		}
+		maps = append(maps, NewMap(s.Node))
+		opts = append(opts, s.Encoding.ToString(streamType, counts[streamType])...)
+		if streamType == "v" {
+			// segments of every representation should start at the same frames
+			opts = append(opts, s.Encoding.fixedGOPOptions(streamType, counts[streamType])...)
+		}
+		counts[streamType]++
+	}
+
+	// streams are often encoded from the same filtered stream, such as renditions scaled from one video
+	insertSplitAcross(nodes)
+
+	sets := make([]string, 0, 2)
+	for _, streamType := range []string{"v", "a"} {
+		if counts[streamType] > 0 {
+			sets = append(sets, fmt.Sprintf("id=%v,streams=%v", len(sets), streamType))
+		}
+	}
+	out.AdaptationSets = strings.Join(sets, " ")
+	if out.ManifestName == "" {
+		out.ManifestName = "manifest.mpd"
+	}
+
+	exec := NewFfmpegExecutor(maps, "", opts)
+	exec.SetOutput(&out)
+
+	return exec, nodes, nil
+}
+
+// validateDASHStream checks that the codec of s can be packaged and that, for video, every segment starts with a
+// keyframe, which requires segment duration to be a multiple of the keyframe interval. If only GOPSize is set, the
+// interval is fixed by NewDASHExecutor.
+func validateDASHStream(s DASHStream, streamType string, segment time.Duration) error {
+	if s.Encoding.Codec == "" {
+		return fmt.Errorf("codec should be set")
+	}
+	if !dashCodecs[streamType][s.Encoding.Codec] {
+		return fmt.Errorf("codec %v is not supported by dash", s.Encoding.Codec)
+	}
+	if streamType == "a" {
+		return nil
+	}
+
+	interval, ok := s.Encoding.keyframeInterval(Info(s.Node))
+	if !ok {
+		return fmt.Errorf("keyframe interval cannot be known, set KeyframeInterval or GOPSize of a stream with known frame rate")
+	}
+	// intervals derived from frame rate are rounded to nanoseconds, hence a millisecond of tolerance
+	if rem := segment % interval; rem > time.Millisecond && interval-rem > time.Millisecond {
+		return fmt.Errorf("segment duration %v is not a multiple of keyframe interval %v", segment, interval)
+	}
+
+	return nil
+}
+
+func boolFlag(b bool) string {
+	if b {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,84 @@
+package ffmpegtree
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDASHOutput(t *testing.T) {
+	i := probedInput(t)
+	hd := NewScaleFilterNode(i, 1280, 720, false)
+	audio := NewSelectStreamNode(i, AudioStream)
+
+	exec, nodes, err := NewDASHExecutor(
+		DASHOutput{SegmentDuration: 4 * time.Second, UseTemplate: true, UseTimeline: true},
+		DASHStream{Node: hd, Encoding: EncodingOptions{Codec: "libx264", Bitrate: "3M", GOPSize: 60}},
+		DASHStream{Node: audio, Audio: true, Encoding: EncodingOptions{Codec: "aac", Bitrate: "128k"}},
+	)
+	require.NoError(t, err)
+	cmd := exec.ToFfmpeg(nodes...)
+
+	require.Equal(t, "[0:0]scale=1280:720["+hd.GetOutStreamName()+"]", cmd.FilterComplex())
+	require.Equal(t, []string{
+		"-map", "[" + hd.GetOutStreamName() + "]", "-map", "0:a:0",
+		"-c:v:0", "libx264", "-b:v:0", "3M", "-g:v:0", "60", "-keyint_min:v:0", "60", "-sc_threshold:v:0", "0",
+		"-c:a:0", "aac", "-b:a:0", "128k",
+		"-f", "dash", "-seg_duration", "4", "-use_template", "1", "-use_timeline", "1",
+		"-adaptation_sets", "id=0,streams=v id=1,streams=a", "manifest.mpd",
+	}, []string(cmd[4:]))
+
+	t.Run("streams share filters", func(t *testing.T) {
+		c := NewCurvesFilter(probedInput(t), "vintage")
+		hd, sd := NewScaleFilterNode(c, 1280, 720, false), NewScaleFilterNode(c, 640, 360, false)
+
+		exec, nodes, err := NewDASHExecutor(
+			DASHOutput{SegmentDuration: 4 * time.Second},
+			DASHStream{Node: hd, Encoding: EncodingOptions{Codec: "libx264", KeyframeInterval: 2 * time.Second}},
+			DASHStream{Node: sd, Encoding: EncodingOptions{Codec: "libx264", KeyframeInterval: 2 * time.Second}},
+		)
+		require.NoError(t, err)
+		cmd := exec.ToFfmpeg(nodes...)
+
+		// every label is written once and read at most once
+		require.Contains(t, cmd.FilterComplex(), "[0:0]curves=preset=vintage,split[")
+		for _, label := range regexp.MustCompile(`\[\w+]`).FindAllString(cmd.FilterComplex(), -1) {
+			require.LessOrEqual(t, strings.Count(cmd.FilterComplex(), label), 2, label)
+		}
+	})
+
+	t.Run("keyframes should be aligned with segments", func(t *testing.T) {
+		_, _, err := NewDASHExecutor(
+			DASHOutput{SegmentDuration: 4 * time.Second},
+			DASHStream{Node: hd, Encoding: EncodingOptions{Codec: "libx264", GOPSize: 90}},
+		)
+		require.Error(t, err)
+
+		// forced keyframes are enough, scene change detection is left as is
+		exec, _, err := NewDASHExecutor(
+			DASHOutput{SegmentDuration: 4 * time.Second},
+			DASHStream{Node: hd, Encoding: EncodingOptions{Codec: "libx264", GOPSize: 60, KeyframeInterval: time.Second}},
+		)
+		require.NoError(t, err)
+		require.NotContains(t, exec.ToFfmpeg(hd), "-sc_threshold:v:0")
+	})
+
+	t.Run("keyframe interval should be known", func(t *testing.T) {
+		_, _, err := NewDASHExecutor(
+			DASHOutput{SegmentDuration: 4 * time.Second},
+			DASHStream{Node: NewInputNode("vid.mp4", nil, nil), Encoding: EncodingOptions{Codec: "libx264", GOPSize: 60}},
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("codec should be supported", func(t *testing.T) {
+		_, _, err := NewDASHExecutor(
+			DASHOutput{SegmentDuration: 4 * time.Second},
+			DASHStream{Node: audio, Audio: true, Encoding: EncodingOptions{Codec: "mp3"}},
+		)
+		require.Error(t, err)
+	})
+}
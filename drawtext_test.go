@@ -0,0 +1,39 @@
+package ffmpegtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawTextOptions(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+
+	f := NewDrawTextFilter(i, "hello", "white", "10", "20", 0, 24)
+	f.FontFile = "/fonts/Inter Bold.ttf"
+	f.Box, f.BoxColor, f.BoxBorderW = true, "black@0.5", 8
+	f.BorderW, f.BorderColor = 2, "black"
+	f.ShadowColor, f.ShadowX, f.ShadowY = "gray", 2, 2
+	f.Alpha = Min(Num(1), T())
+	f.LineSpacing = 4
+	require.Equal(t,
+		"drawtext=expansion=none:text=''hello'':fontcolor=white:fontsize=24:x=10:y=20:fontfile=/fonts/Inter Bold.ttf:box=1:boxcolor=black@0.5:boxborderw=8:borderw=2:bordercolor=black:shadowcolor=gray:shadowx=2:shadowy=2:alpha='min(1,t)':line_spacing=4",
+		f.FilterString())
+
+	f = NewDrawTextFilter(i, "hello", "", "10", "20", 0, 24)
+	f.Font = "Arial:style=Bold"
+	require.Equal(t, `drawtext=expansion=none:text=''hello'':fontcolor=black:fontsize=24:x=10:y=20:font=Arial\\:style=Bold`, f.FilterString())
+}
+
+func TestDynamicText(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+
+	f := NewDynamicTextFilter(i, LiteralText("it's 100% ")+LocalTimeText("%H:%M")+" / "+PtsText("hms")+" #"+FrameNumberText(), "white", "10", "10", 24)
+	require.Equal(t,
+		`drawtext=text='it'\\\''s 100\\% %{localtime\:%H\\\:%M} / %{pts\:hms} #%{n}':fontcolor=white:fontsize=24:x=10:y=10`,
+		f.FilterString())
+
+	f = NewTextFileFilter(i, "/tmp/score.txt", "white", "10", "10", 24)
+	f.Reload = true
+	require.Equal(t, "drawtext=expansion=none:textfile=/tmp/score.txt:reload=1:fontcolor=white:fontsize=24:x=10:y=10", f.FilterString())
+}
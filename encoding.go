@@ -0,0 +1,85 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"time"
+)
+
+// EncodingOptions are encoder options of an output stream. Zero values are left to ffmpeg's defaults.
+type EncodingOptions struct {
+	Codec string
+
+	// Bitrate, MaxRate and BufSize are in ffmpeg's syntax such as '5M' or '800k'.
+	Bitrate, MaxRate, BufSize string
+
+	// GOPSize is the maximum number of frames between keyframes.
+	GOPSize int
+
+	// KeyframeInterval forces keyframes at multiples of it with '-force_key_frames'.
+	KeyframeInterval time.Duration
+}
+
+// ToString returns options of the idx'th output stream of streamType, which is 'v' or 'a'.
+func (o EncodingOptions) ToString(streamType string, idx int) []string {
+	res := make([]string, 0)
+	if o.Codec != "" {
+		res = append(res, fmt.Sprintf("-c:%v:%v", streamType, idx), o.Codec)
+	}
+	res = append(res, rateControlOptions(streamType, idx, o.Bitrate, o.MaxRate, o.BufSize)...)
+	if o.GOPSize > 0 {
+		res = append(res, fmt.Sprintf("-g:%v:%v", streamType, idx), fmt.Sprintf("%v", o.GOPSize))
+	}
+	if o.KeyframeInterval > 0 {
+		res = append(res, fmt.Sprintf("-force_key_frames:%v:%v", streamType, idx), forceKeyFramesExpr(o.KeyframeInterval))
+	}
+
+	return res
+}
+
+// fixedGOPOptions returns options which make keyframes of the idx'th output stream of streamType exactly GOPSize frames
+// apart, since GOPSize is only the maximum and encoders add keyframes at scene changes. Nothing is returned if
+// KeyframeInterval is set, which forces keyframes itself.
+func (o EncodingOptions) fixedGOPOptions(streamType string, idx int) []string {
+	if o.GOPSize <= 0 || o.KeyframeInterval > 0 {
+		return nil
+	}
+
+	return []string{
+		fmt.Sprintf("-keyint_min:%v:%v", streamType, idx), fmt.Sprintf("%v", o.GOPSize),
+		fmt.Sprintf("-sc_threshold:%v:%v", streamType, idx), "0",
+	}
+}
+
+// keyframeInterval returns the time between keyframes. It is derived from the frame rate of the stream if only
+// GOPSize is set. ok is false if it cannot be known.
+func (o EncodingOptions) keyframeInterval(info StreamInfo) (d time.Duration, ok bool) {
+	if o.KeyframeInterval > 0 {
+		return o.KeyframeInterval, true
+	}
+	if o.GOPSize > 0 && info.FPS != nil && *info.FPS > 0 {
+		return time.Duration(float64(o.GOPSize) / *info.FPS * float64(time.Second)), true
+	}
+
+	return 0, false
+}
+
+// forceKeyFramesExpr returns a '-force_key_frames' value which forces a keyframe at every multiple of d.
+func forceKeyFramesExpr(d time.Duration) string {
+	return fmt.Sprintf("expr:gte(t,n_forced*%v)", fmtSeconds(d))
+}
+
+// rateControlOptions returns bitrate options of the idx'th output stream of streamType.
+func rateControlOptions(streamType string, idx int, bitrate, maxRate, bufSize string) []string {
+	res := make([]string, 0)
+	if bitrate != "" {
+		res = append(res, fmt.Sprintf("-b:%v:%v", streamType, idx), bitrate)
+	}
+	if maxRate != "" {
+		res = append(res, fmt.Sprintf("-maxrate:%v:%v", streamType, idx), maxRate)
+	}
+	if bufSize != "" {
+		res = append(res, fmt.Sprintf("-bufsize:%v:%v", streamType, idx), bufSize)
+	}
+
+	return res
+}
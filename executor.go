@@ -2,6 +2,7 @@ package ffmpegtree
 
 import (
 	"fmt"
+	"io"
 	"strings"
 )
 
@@ -42,9 +43,59 @@ type FFmpegExecutor struct {
 	maps       []IMap
 	outName    string
 	outOptions []string
+	optimizer  *Optimizer
+	pipes      Pipes
+	outFormat  string
+	output     IOutput
+
+	simpleFilterGraphs bool
+	outputArgs         []string
+	metadataSources    []metadataSource
+	subtitleTracks     int
+}
+
+// SetSimpleFilterGraphs makes the executor write '-vf' and '-af' options instead of '-filter_complex' when the graph
+// consists of at most one linear chain per stream type. Chains starting from an input stream selected by its index are
+// written as '-vf' or '-af' only if the input is probed.
+func (e *FFmpegExecutor) SetSimpleFilterGraphs(enabled bool) {
+	e.simpleFilterGraphs = enabled
+}
+
+// SetOptimizer sets the optimizer which rewrites the graph before it is compiled. Nil disables optimization.
+func (e *FFmpegExecutor) SetOptimizer(o *Optimizer) {
+	e.optimizer = o
+}
+
+// SetOutputWriter makes the command write its output to w through stdout in the given format.
+func (e *FFmpegExecutor) SetOutputWriter(w io.Writer, format string) {
+	e.pipes.Stdout = w
+	e.outName = "pipe:1"
+	e.outFormat = format
+}
+
+// Pipes returns the streams which should be wired to ffmpeg to run the last command returned by ToFfmpeg.
+func (e *FFmpegExecutor) Pipes() Pipes {
+	return e.pipes
 }
 
 func (e *FFmpegExecutor) ToFfmpeg(nodes ...INode) FfmpegCommand {
+	if e.optimizer != nil {
+		// readers are told apart only by their pipes, hence they are wired before the graph is rewritten. they are wired
+		// again below in the order inputs show up in the output
+		e.wireReaders(readersOf(nodes, e.maps))
+		nodes = e.optimizer.Optimize(nodes, e.maps)
+	}
+
+	// streams written to tee slaves are selected by their output index, so output nodes are mapped explicitly to have
+	// them in a known order if nothing is mapped
+	if _, ok := e.output.(*TeeOutput); ok && len(e.maps) == 0 {
+		for _, node := range nodes {
+			if _, ok := node.(IInputNode); !ok {
+				e.maps = append(e.maps, NewMap(node))
+			}
+		}
+	}
+
 	for _, node := range nodes {
 		// find all IInputNode and assign their indexes. it will affect the order they show up in the output
 		e.setInputIdx(node)
@@ -56,14 +107,31 @@ func (e *FFmpegExecutor) ToFfmpeg(nodes ...INode) FfmpegCommand {
 		// insert split nodes if a stream is input to more than one node
 		e.insertSplit(node)
 	}
+	e.setMappedInputIdx()
+	e.setMetadataInputIdx()
+
+	// inputs read from io.Reader's are assigned file descriptors in the order they show up in the output
+	e.wirePipes()
 
 	// each node can access its inputs but cannot access to nodes which depends on itself. traverse tree
 	// and save dependencies in a hashmap structure. it will be useful while executing tree.
 	e.dependents = GetDependents(nodes...)
 
-	// start traversal from root node
-	e.q = nodes
-	r := e.toFfmpeg()
+	// graphs which consist of one linear chain per stream type can be written as simple filters if it is preferred
+	var filterArgs []string
+	simple := make(map[string]bool)
+	if e.simpleFilterGraphs {
+		filterArgs, simple = e.toSimpleFilters(nodes)
+	}
+
+	if filterArgs == nil {
+		// start traversal from root node
+		e.q = nodes
+		if r := e.toFfmpeg(); r != "" {
+			// ffmpeg rejects an empty filter graph, so it is omitted when streams are only mapped from inputs
+			filterArgs = []string{"-filter_complex", r}
+		}
+	}
 
 	// generate input options which are in the form of "-i ***.mp4"
 	inputs := make([]string, 0, len(e.inputs))
@@ -74,15 +142,26 @@ func (e *FFmpegExecutor) ToFfmpeg(nodes ...INode) FfmpegCommand {
 	// generate map options which are in the form of "-map '0:0'" or "-map '[var_1:0]'"
 	maps := make([]string, 0, len(e.maps))
 	for _, iMap := range e.maps {
+		if simple[iMap.GetStreamNode().GetID()] {
+			// already mapped with its simple filter
+			continue
+		}
 		maps = append(maps, iMap.ToString()...)
 	}
 
 	// put it all together
 	res := make([]string, 0)
 	res = append(res, inputs...)
-	res = append(res, "-filter_complex", r)
+	res = append(res, filterArgs...)
 	res = append(res, maps...)
+	res = append(res, e.outputOptions()...)
 	res = append(res, e.outOptions...)
+	if e.output != nil {
+		return append(res, e.output.ToString()...)
+	}
+	if e.pipes.Stdout != nil {
+		res = append(res, pipeOutputOptions(e.outFormat, e.outOptions)...)
+	}
 	res = append(res, e.outName)
 	return res
 }
@@ -146,6 +225,94 @@ func (e *FFmpegExecutor) toFfmpeg() string {
 	return strings.Join(e.acc, ";")
 }
 
+// toSimpleFilters returns '-vf' and '-af' options, each one preceded by the map of the stream it filters, if the graph
+// consists of at most one linear chain per stream type. Otherwise, it returns nil. Returned map contains ids of chains'
+// last nodes.
+func (e *FFmpegExecutor) toSimpleFilters(nodes []INode) ([]string, map[string]bool) {
+	res := make([]string, 0)
+	simple := make(map[string]bool)
+	flags := map[string]string{"v": "-vf", "a": "-af"}
+	for _, node := range nodes {
+		if _, ok := node.(IFilterNode); !ok {
+			return nil, nil
+		}
+
+		c := make(Chain, 0)
+		var t INode = node
+		for {
+			fn, ok := t.(IFilterNode)
+			if !ok {
+				break
+			}
+			// splits and merges cannot be expressed as a chain and a shared node means the graph is not linear
+			if len(t.GetInputs()) != 1 || len(e.dependents.Get(t)) > 1 {
+				return nil, nil
+			}
+			c = append(c, fn)
+			t = t.GetInputs()[0]
+		}
+
+		ssn, ok := t.(*SelectStreamNode)
+		if !ok {
+			return nil, nil
+		}
+
+		// '-map 0:a' maps every audio stream while '[0:a]' is only the first one, and a stream selected by its index,
+		// such as inputs directly fed into filters, is filtered only if probing tells its type
+		streamType, spec := ssn.idx, ssn.idx+":0"
+		if streamType != "v" && streamType != "a" {
+			streamType, spec = probedStreamType(ssn.input, ssn.idx), ssn.idx
+		}
+		flag, ok := flags[streamType]
+		if !ok {
+			return nil, nil
+		}
+		delete(flags, streamType)
+
+		res = append(res, "-map", fmt.Sprintf("%v:%v", ssn.input.GetInputIdx(), spec), flag, c.ToString(false))
+		simple[node.GetID()] = true
+	}
+
+	// simple filters apply to every output stream of their type, so no other stream of the same type can be mapped
+	for _, m := range e.maps {
+		if simple[m.GetStreamNode().GetID()] {
+			continue
+		}
+		fromInput, ok := m.(*MapFromInputNode)
+		if !ok {
+			return nil, nil
+		}
+		if _, ok := flags[fromInput.stream]; !ok {
+			return nil, nil
+		}
+	}
+
+	return res, simple
+}
+
+// probedStreamType returns 'v' or 'a' for the stream of input at idx if input is probed and the stream is a video or
+// an audio stream.
+func probedStreamType(input IInputNode, idx string) string {
+	p, ok := input.(interface{ GetMetadata() *ProbeResult })
+	if !ok || p.GetMetadata() == nil {
+		return ""
+	}
+
+	stream, ok := p.GetMetadata().Stream(idx)
+	if !ok {
+		return ""
+	}
+
+	switch stream.CodecType {
+	case "video":
+		return "v"
+	case "audio":
+		return "a"
+	}
+
+	return ""
+}
+
 // toChain creates a chain of IFilterNode's by adding given node then, starts to follow every node's inputs and adds it to chain
 // if the node has only one child and one dependency
 func (e *FFmpegExecutor) toChain(tree INode) (c Chain, ret INode) {
@@ -198,7 +365,17 @@ func (e *FFmpegExecutor) insertSelectStream(t INode) {
 // insertSplit traverses the graph and inserts an ISplitNode if a stream is used as input for more than one times.
 // This is required by ffmpeg syntax.
 func (e *FFmpegExecutor) insertSplit(t INode) {
-	d := GetDependents(t)
+	splitShared(GetDependents(t))
+}
+
+// insertSplitAcross inserts split nodes for streams which are shared by several output nodes. ToFfmpeg splits streams
+// only within each output node, hence helpers which build several outputs from the same streams use it.
+func insertSplitAcross(nodes []INode) {
+	splitShared(GetDependents(nodes...))
+}
+
+// splitShared inserts a split node after every node which is input to more than one of its dependents.
+func splitShared(d *DependentsMap) {
 	for _, currNode := range d.Keys() {
 		if _, ok := currNode.(ISplitNode); ok {
 			continue
@@ -226,6 +403,7 @@ func (e *FFmpegExecutor) insertSplit(t INode) {
 // setInputIdx traverses the graph from a given node and discovers all input nodes and assign them an index.
 func (e *FFmpegExecutor) setInputIdx(t INode) {
 	if i, ok := t.(IInputNode); ok && !e.isInInputs(i) {
+		i.SetInputIdx(len(e.inputs))
 		e.inputs = append(e.inputs, i)
 		return
 	}
@@ -238,18 +416,78 @@ func (e *FFmpegExecutor) setInputIdx(t INode) {
 			e.inputs = append(e.inputs, in)
 		}
 	}
+}
 
-	// add input nodes which are mapped but is not used in graph at all
+// setMappedInputIdx assigns indexes to input nodes which are mapped but are not used in graph at all, such as when
+// streams are copied without any filtering.
+func (e *FFmpegExecutor) setMappedInputIdx() {
 	for _, iMap := range e.maps {
 		n := iMap.GetStreamNode()
 		in, ok := n.(IInputNode)
-		if ok && d.Get(in) == nil && !e.isInInputs(in) {
+		if ok && !e.isInInputs(in) {
 			in.SetInputIdx(len(e.inputs))
 			e.inputs = append(e.inputs, in)
 		}
 	}
 }
 
+// wirePipes assigns file descriptors to inputs which are read from io.Reader's. The first one is fed through stdin and
+// the others through extra files, which start from 3.
+func (e *FFmpegExecutor) wirePipes() {
+	readers := make([]*ReaderInputNode, 0)
+	for _, in := range e.inputs {
+		if r, ok := in.(*ReaderInputNode); ok {
+			readers = append(readers, r)
+		}
+	}
+
+	e.wireReaders(readers)
+}
+
+func (e *FFmpegExecutor) wireReaders(readers []*ReaderInputNode) {
+	e.pipes.Stdin, e.pipes.ExtraFiles = nil, nil
+	for _, r := range readers {
+		if e.pipes.Stdin == nil {
+			r.setFd(0)
+			e.pipes.Stdin = r.Reader
+			continue
+		}
+
+		r.setFd(3 + len(e.pipes.ExtraFiles))
+		e.pipes.ExtraFiles = append(e.pipes.ExtraFiles, r.Reader)
+	}
+}
+
+// readersOf returns readers in the graphs of nodes and the ones which are only mapped, each one once.
+func readersOf(nodes []INode, maps []IMap) []*ReaderInputNode {
+	roots := append([]INode{}, nodes...)
+	for _, m := range maps {
+		roots = append(roots, m.GetStreamNode())
+	}
+
+	res := make([]*ReaderInputNode, 0)
+	visited := make(map[string]bool)
+	var visit func(n INode)
+	visit = func(n INode) {
+		if visited[n.GetID()] {
+			return
+		}
+		visited[n.GetID()] = true
+
+		if r, ok := n.(*ReaderInputNode); ok {
+			res = append(res, r)
+		}
+		for _, inp := range n.GetInputs() {
+			visit(inp)
+		}
+	}
+	for _, n := range roots {
+		visit(n)
+	}
+
+	return res
+}
+
 func (e *FFmpegExecutor) isMapped(n INode) bool {
 	for _, m := range e.maps {
 		if m.GetStreamNode().GetID() == n.GetID() {
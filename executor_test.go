@@ -3,7 +3,6 @@ package ffmpegtree
 import (
 	"fmt"
 	"regexp"
-	"strings"
 	"testing"
 	"time"
 
@@ -213,7 +212,7 @@ func TestWithTimeline(t *testing.T) {
 
 		args := Select([]INode{res}, "out.mp4", nil)
 
-		require.Equal(t, `[0:0]curves=preset=vintage:enable='gte(t, 3.00)',scale=100:100,setsar=1:1`, args.FilterComplex())
+		require.Equal(t, `[0:0]curves=preset=vintage:enable='gte(t,3)',scale=100:100,setsar=1:1`, args.FilterComplex())
 	})
 
 	t.Run("apply negative then back to normal", func(t *testing.T) {
@@ -236,7 +235,43 @@ func TestWithTimeline(t *testing.T) {
 
 		args := Select([]INode{res}, "out.mp4", nil)
 
-		require.Equal(t, `[0:0]drawtext=expansion=none:text=''now it is normal'':fontcolor=black:fontsize=30:x=(w-text_w)/2:y=(40-text_h)/2+0:enable='lte(t, 3.00)',curves=preset=negative:enable='between(t, 3.00, 5.00)',drawtext=expansion=none:text=''now it is negative'':fontcolor=black:fontsize=30:x=(w-text_w)/2:y=(40-text_h)/2+0:enable='between(t, 3.00, 5.00)',drawtext=expansion=none:text=''now it is back to normal'':fontcolor=black:fontsize=20:x=(w-text_w)/2:y=(40-text_h)/2+0:enable='gte(t, 5.00)',scale=350:350,setsar=1:1`, args.FilterComplex())
+		require.Equal(t, `[0:0]drawtext=expansion=none:text=''now it is normal'':fontcolor=black:fontsize=30:x=(w-text_w)/2:y=(40-text_h)/2+0:enable='lte(t,3)',curves=preset=negative:enable='between(t,3,5)',drawtext=expansion=none:text=''now it is negative'':fontcolor=black:fontsize=30:x=(w-text_w)/2:y=(40-text_h)/2+0:enable='between(t,3,5)',drawtext=expansion=none:text=''now it is back to normal'':fontcolor=black:fontsize=20:x=(w-text_w)/2:y=(40-text_h)/2+0:enable='gte(t,5)',scale=350:350,setsar=1:1`, args.FilterComplex())
+	})
+
+	t.Run("blur in several windows", func(t *testing.T) {
+		i := NewInputNode("./test_assets/test-vid.mp4", nil, nil)
+		b := NewBoxBlurFilter(i, "5", "5", 1)
+		b.Window(time.Second, 2500*time.Millisecond)
+		b.Window(4*time.Second, 5*time.Second)
+		b.FrameWindow(200, 250)
+
+		args := Select([]INode{b}, "out.mp4", nil)
+
+		require.Equal(t, `[0:0]boxblur=luma_radius=5:chroma_radius=5:luma_power=1:enable='between(t,1,2.5)+between(t,4,5)+between(n,200,250)'`, args.FilterComplex())
+	})
+
+	t.Run("custom expression is combined with windows", func(t *testing.T) {
+		i := NewInputNode("./test_assets/test-vid.mp4", nil, nil)
+		v := NewVolumeFilter(NewSelectStreamNode(i, AudioStream), 0.5)
+		v.EnableWhen(Gt(Sin(T()), Num(0)))
+		v.Since(3)
+
+		args := Select([]INode{v}, "out.mp4", nil)
+
+		require.Equal(t, `[0:a]volume=0.50:enable='(gt(sin(t),0))*gte(t,3)'`, args.FilterComplex())
+	})
+
+	t.Run("since and until keep fractions like windows", func(t *testing.T) {
+		i := NewInputNode("./test_assets/test-vid.mp4", nil, nil)
+		r := NewRotateFilter(i, "PI/6")
+		r.Since(1.125)
+		r.Until(2.5)
+		f := NewFadeFilter(r, FadeIn, 0, time.Second)
+		f.Window(0, 1125*time.Millisecond)
+
+		args := Select([]INode{f}, "out.mp4", nil)
+
+		require.Equal(t, `[0:0]rotate=PI/6:enable='between(t,1.125,2.5)',fade=type=in:start_time=0:duration=1:enable='between(t,0,1.125)'`, args.FilterComplex())
 	})
 }
 
@@ -253,3 +288,87 @@ func TestAudioStreams(t *testing.T) {
 		require.Equal(t, `[0:a]aecho=in_gain=0.50:out_gain=0.90:delays=1000:decays=0.30;[0:0]scale=300:300,setsar=1:1`, cmd.FilterComplex())
 	})
 }
+
+func TestInputOptions(t *testing.T) {
+	offset := 1500 * time.Millisecond
+	accurate := false
+	i, err := NewInputNodeWithOptions("video.h264", InputOptions{
+		Format:          "h264",
+		Re:              true,
+		ThreadQueueSize: 512,
+		AccurateSeek:    &accurate,
+		ItsOffset:       &offset,
+		FrameRate:       "30000/1001",
+		VideoDecoder:    "h264_cuvid",
+		Extra:           []InputOption{{"-probesize", "32"}, {"-autorotate", ""}},
+	})
+	require.NoError(t, err)
+	length := 5 * time.Second
+	i.Len = &length
+
+	cmd := Select([]INode{NewScaleFilterNode(i, 100, 100, false)}, "out.mp4", nil)
+	require.Equal(t, FfmpegCommand{
+		"-t", "00:00:05",
+		"-f", "h264", "-re", "-thread_queue_size", "512", "-noaccurate_seek", "-itsoffset", "1.5", "-r", "30000/1001",
+		"-c:v", "h264_cuvid", "-probesize", "32", "-autorotate",
+		"-i", "video.h264",
+		"-filter_complex", "[0:0]scale=100:100",
+		"out.mp4",
+	}, cmd)
+
+	_, err = NewInputNodeWithOptions("video.mp4", InputOptions{Extra: []InputOption{{"-i", "other.mp4"}}})
+	require.Error(t, err)
+	_, err = NewInputNodeWithOptions("video.mp4", InputOptions{Extra: []InputOption{{"probesize", "32"}}})
+	require.Error(t, err)
+}
+
+func TestWithoutFilterGraph(t *testing.T) {
+	t.Run("stream copy", func(t *testing.T) {
+		i1, i2 := NewInputNode("video.mp4", nil, nil), NewInputNode("audio.m4a", nil, nil)
+
+		cmd := Select(nil, "out.mp4", []string{"-c", "copy"}, NewMap(i1, "v"), NewMap(i2, "a"))
+		require.Equal(t, FfmpegCommand{"-i", "video.mp4", "-i", "audio.m4a", "-map", "0:v", "-map", "1:a", "-c", "copy", "out.mp4"}, cmd)
+	})
+
+	t.Run("simple filters", func(t *testing.T) {
+		i := probedInput(t)
+		v := NewCurvesFilter(NewScaleFilterNode(i, 100, 100, true), "vintage")
+		a := NewVolumeFilter(NewSelectStreamNode(i, AudioStream), 0.5)
+
+		exec := NewFfmpegExecutor([]IMap{NewMap(v)}, "out.mp4", nil)
+		exec.SetSimpleFilterGraphs(true)
+		cmd := exec.ToFfmpeg(v, a)
+		require.Equal(t, FfmpegCommand{"-i", "vid.mp4", "-map", "0:0", "-vf", "scale=100:100,setsar=1:1,curves=preset=vintage", "-map", "0:a:0", "-af", "volume=0.50", "out.mp4"}, cmd)
+
+		v2 := NewScaleFilterNode(NewSelectStreamNode(NewInputNode("video.mp4", nil, nil), VideoStream), 100, 100, false)
+		exec = NewFfmpegExecutor(nil, "out.mp4", nil)
+		exec.SetSimpleFilterGraphs(true)
+		cmd = exec.ToFfmpeg(v2)
+		require.Equal(t, FfmpegCommand{"-i", "video.mp4", "-map", "0:v:0", "-vf", "scale=100:100", "out.mp4"}, cmd)
+	})
+
+	t.Run("stream of unknown type", func(t *testing.T) {
+		// the first stream of an input which is not probed may as well be audio
+		i := NewInputNode("video.mp4", nil, nil)
+		exec := NewFfmpegExecutor(nil, "out.mp4", nil)
+		exec.SetSimpleFilterGraphs(true)
+		cmd := exec.ToFfmpeg(NewScaleFilterNode(i, 100, 100, false))
+		require.Equal(t, "[0:0]scale=100:100", cmd.FilterComplex())
+	})
+
+	t.Run("falls back to filter_complex", func(t *testing.T) {
+		i := NewInputNode("video.mp4", nil, nil)
+		s := NewScaleFilterNode(i, 100, 100, false)
+		ov := NewOverlayIntoMiddleFilterNode(s, NewSelectStreamNode(NewInputNode("logo.png", nil, nil), VideoStream))
+
+		exec := NewFfmpegExecutor(nil, "out.mp4", nil)
+		exec.SetSimpleFilterGraphs(true)
+		cmd := exec.ToFfmpeg(ov)
+		require.Contains(t, cmd.FilterComplex(), "overlay")
+
+		exec = NewFfmpegExecutor([]IMap{NewMap(NewInputNode("other.mp4", nil, nil), "v")}, "out.mp4", nil)
+		exec.SetSimpleFilterGraphs(true)
+		cmd = exec.ToFfmpeg(NewScaleFilterNode(i, 100, 100, false))
+		require.Equal(t, "[0:0]scale=100:100", cmd.FilterComplex())
+	})
+}
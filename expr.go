@@ -0,0 +1,302 @@
+package ffmpegtree
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Expr is a node of ffmpeg's expression language. It can be rendered to be used in filter options and evaluated in go,
+// which is useful for testing and previewing.
+type Expr interface {
+	// String returns expression in ffmpeg's syntax without any escaping, as it would be written in ffmpeg docs.
+	String() string
+
+	// Eval evaluates expression with given variables. Unknown variables evaluate to NaN.
+	Eval(vars Vars) float64
+
+	// precedence is used to decide where parentheses are needed while rendering
+	precedence() int
+}
+
+// Vars maps variable names such as 't' or 'main_w' to their values.
+type Vars map[string]float64
+
+// At returns variables with only time set.
+func At(t float64) Vars {
+	return Vars{"t": t}
+}
+
+// ToExpression converts e to an Expression which is quoted when it is placed into a filter option.
+func ToExpression(e Expr) Expression {
+	return Expression(e.String())
+}
+
+// Quoted returns e escaped to be placed into a filter option as is, such as RotateFilter's angle.
+func Quoted(e Expr) string {
+	return ToExpression(e).String()
+}
+
+const (
+	precAdd = iota + 1
+	precMul
+	precUnary
+	precAtom
+)
+
+type num float64
+
+func (n num) String() string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
+
+func (n num) Eval(Vars) float64 {
+	return float64(n)
+}
+
+func (n num) precedence() int {
+	if n < 0 {
+		return precUnary
+	}
+	return precAtom
+}
+
+// Num returns a numeric constant.
+func Num(f float64) Expr {
+	return num(f)
+}
+
+type variable string
+
+func (v variable) String() string {
+	return string(v)
+}
+
+func (v variable) Eval(vars Vars) float64 {
+	if val, ok := vars[string(v)]; ok {
+		return val
+	}
+	switch v {
+	case "PI":
+		return math.Pi
+	case "E":
+		return math.E
+	}
+
+	return math.NaN()
+}
+
+func (v variable) precedence() int {
+	return precAtom
+}
+
+// Var returns a variable or a constant known by the filter which evaluates the expression.
+func Var(name string) Expr {
+	return variable(name)
+}
+
+// T is the timestamp in seconds.
+func T() Expr { return variable("t") }
+
+// N is the sequential number of the frame, starting from 0.
+func N() Expr { return variable("n") }
+
+// W is the width of the input, named 'w' or 'W' depending on the filter.
+func W() Expr { return variable("w") }
+
+// H is the height of the input, named 'h' or 'H' depending on the filter.
+func H() Expr { return variable("h") }
+
+func MainW() Expr    { return variable("main_w") }
+func MainH() Expr    { return variable("main_h") }
+func OverlayW() Expr { return variable("overlay_w") }
+func OverlayH() Expr { return variable("overlay_h") }
+func TextW() Expr    { return variable("text_w") }
+func TextH() Expr    { return variable("text_h") }
+func Pi() Expr       { return variable("PI") }
+
+type raw string
+
+func (r raw) String() string {
+	return string(r)
+}
+
+func (r raw) Eval(Vars) float64 {
+	return math.NaN()
+}
+
+func (r raw) precedence() int {
+	// nothing is known about it, so it is always parenthesized when it is an operand
+	return 0
+}
+
+// Raw wraps an expression written in ffmpeg's syntax. It cannot be evaluated in go.
+func Raw(s string) Expr {
+	return raw(s)
+}
+
+type binOp struct {
+	op   byte
+	l, r Expr
+}
+
+func (b binOp) String() string {
+	l, r := b.l.String(), b.r.String()
+	if b.l.precedence() < b.precedence() {
+		l = "(" + l + ")"
+	}
+	// right operand needs parentheses on equal precedence too, since a-(b-c) is not a-b-c
+	if b.r.precedence() < b.precedence() || (b.r.precedence() == b.precedence() && b.op != '+' && b.op != '*') ||
+		b.r.precedence() == precUnary {
+		r = "(" + r + ")"
+	}
+
+	return l + string(b.op) + r
+}
+
+func (b binOp) Eval(vars Vars) float64 {
+	l, r := b.l.Eval(vars), b.r.Eval(vars)
+	switch b.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	default:
+		return l / r
+	}
+}
+
+func (b binOp) precedence() int {
+	if b.op == '+' || b.op == '-' {
+		return precAdd
+	}
+	return precMul
+}
+
+func Add(l, r Expr) Expr { return binOp{op: '+', l: l, r: r} }
+func Sub(l, r Expr) Expr { return binOp{op: '-', l: l, r: r} }
+func Mul(l, r Expr) Expr { return binOp{op: '*', l: l, r: r} }
+func Div(l, r Expr) Expr { return binOp{op: '/', l: l, r: r} }
+
+type neg struct {
+	e Expr
+}
+
+func (n neg) String() string {
+	if n.e.precedence() < precAtom {
+		return "-(" + n.e.String() + ")"
+	}
+	return "-" + n.e.String()
+}
+
+func (n neg) Eval(vars Vars) float64 {
+	return -n.e.Eval(vars)
+}
+
+func (n neg) precedence() int {
+	return precUnary
+}
+
+func Neg(e Expr) Expr { return neg{e: e} }
+
+type call struct {
+	name string
+	args []Expr
+	fn   func(args []float64) float64
+}
+
+func (c call) String() string {
+	args := make([]string, 0, len(c.args))
+	for _, a := range c.args {
+		args = append(args, a.String())
+	}
+
+	return c.name + "(" + strings.Join(args, ",") + ")"
+}
+
+func (c call) Eval(vars Vars) float64 {
+	args := make([]float64, 0, len(c.args))
+	for _, a := range c.args {
+		args = append(args, a.Eval(vars))
+	}
+
+	return c.fn(args)
+}
+
+func (c call) precedence() int {
+	return precAtom
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func unary(name string, fn func(float64) float64) func(Expr) Expr {
+	return func(e Expr) Expr {
+		return call{name: name, args: []Expr{e}, fn: func(a []float64) float64 { return fn(a[0]) }}
+	}
+}
+
+func binary(name string, fn func(float64, float64) float64) func(Expr, Expr) Expr {
+	return func(x, y Expr) Expr {
+		return call{name: name, args: []Expr{x, y}, fn: func(a []float64) float64 { return fn(a[0], a[1]) }}
+	}
+}
+
+func ternary(name string, fn func(float64, float64, float64) float64) func(Expr, Expr, Expr) Expr {
+	return func(x, y, z Expr) Expr {
+		return call{name: name, args: []Expr{x, y, z}, fn: func(a []float64) float64 { return fn(a[0], a[1], a[2]) }}
+	}
+}
+
+var (
+	Sin   = unary("sin", math.Sin)
+	Cos   = unary("cos", math.Cos)
+	Tan   = unary("tan", math.Tan)
+	Abs   = unary("abs", math.Abs)
+	Sqrt  = unary("sqrt", math.Sqrt)
+	Exp   = unary("exp", math.Exp)
+	Log   = unary("log", math.Log)
+	Floor = unary("floor", math.Floor)
+	Ceil  = unary("ceil", math.Ceil)
+	Trunc = unary("trunc", math.Trunc)
+	Round = unary("round", math.Round)
+	Not   = unary("not", func(x float64) float64 { return boolToFloat(x == 0) })
+
+	Pow = binary("pow", math.Pow)
+	Min = binary("min", math.Min)
+	Max = binary("max", math.Max)
+
+	// Mod is floored like ffmpeg's mod, so the result has the sign of y unlike math.Mod.
+	Mod = binary("mod", func(x, y float64) float64 { return x - y*math.Floor(x/y) })
+
+	Gte = binary("gte", func(x, y float64) float64 { return boolToFloat(x >= y) })
+	Gt  = binary("gt", func(x, y float64) float64 { return boolToFloat(x > y) })
+	Lte = binary("lte", func(x, y float64) float64 { return boolToFloat(x <= y) })
+	Lt  = binary("lt", func(x, y float64) float64 { return boolToFloat(x < y) })
+	Eq  = binary("eq", func(x, y float64) float64 { return boolToFloat(x == y) })
+
+	// Clip clips x between min and max.
+	Clip = ternary("clip", func(x, min, max float64) float64 { return math.Max(min, math.Min(max, x)) })
+
+	// Between is 1 if x is in [min, max] and 0 otherwise.
+	Between = ternary("between", func(x, min, max float64) float64 { return boolToFloat(x >= min && x <= max) })
+
+	// If evaluates to y when x is not zero and to z otherwise.
+	If = ternary("if", func(x, y, z float64) float64 {
+		if x != 0 {
+			return y
+		}
+		return z
+	})
+)
+
+// Lerp linearly interpolates from a to b as t goes from 0 to 1.
+func Lerp(a, b, t Expr) Expr {
+	return Add(a, Mul(Sub(b, a), t))
+}
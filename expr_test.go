@@ -0,0 +1,47 @@
+package ffmpegtree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr(t *testing.T) {
+	t.Run("render", func(t *testing.T) {
+		require.Equal(t, "t*2+1", Add(Mul(T(), Num(2)), Num(1)).String())
+		require.Equal(t, "(t+1)*2", Mul(Add(T(), Num(1)), Num(2)).String())
+		require.Equal(t, "t-(n-1)", Sub(T(), Sub(N(), Num(1))).String())
+		require.Equal(t, "w*(-2)", Mul(W(), Num(-2)).String())
+		require.Equal(t, "-(t+1)", Neg(Add(T(), Num(1))).String())
+		require.Equal(t, "if(between(t,1,2),main_w-overlay_w,0)", If(Between(T(), Num(1), Num(2)), Sub(MainW(), OverlayW()), Num(0)).String())
+		require.Equal(t, "clip(sin(t*PI),0,1)", Clip(Sin(Mul(T(), Pi())), Num(0), Num(1)).String())
+		require.Equal(t, "(a+b)*2", Mul(Raw("a+b"), Num(2)).String())
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		e := If(Gte(T(), Num(1)), Num(10), Num(20))
+		require.Equal(t, Expression("if(gte(t,1),10,20)"), ToExpression(e))
+		require.Equal(t, "'if(gte(t,1),10,20)'", Quoted(e))
+
+		ov := NewOverlayFilterNode(NewInputNode("a.mp4", nil, nil), NewInputNode("b.mp4", nil, nil), ToExpression(e), "0")
+		require.Equal(t, "overlay=x='if(gte(t,1),10,20)':y='0'", ov.FilterString())
+	})
+
+	t.Run("eval", func(t *testing.T) {
+		e := Lerp(Num(100), Num(200), Div(T(), Num(4)))
+		require.Equal(t, 150.0, e.Eval(At(2)))
+
+		e = If(Between(T(), Num(1), Num(2)), Var("main_w"), Num(0))
+		require.Equal(t, 1920.0, e.Eval(Vars{"t": 1.5, "main_w": 1920}))
+		require.Equal(t, 0.0, e.Eval(Vars{"t": 3, "main_w": 1920}))
+		require.InDelta(t, 1.0, Sin(Div(Pi(), Num(2))).Eval(nil), 1e-9)
+		require.True(t, math.IsNaN(W().Eval(At(0))))
+
+		// mod is floored as in ffmpeg, so the result has the sign of the divisor
+		require.Equal(t, 1.0, Mod(Num(7), Num(3)).Eval(nil))
+		require.Equal(t, 2.0, Mod(Num(-7), Num(3)).Eval(nil))
+		require.Equal(t, -2.0, Mod(Num(7), Num(-3)).Eval(nil))
+		require.InDelta(t, 0.5, Mod(Num(-2.5), Num(1.5)).Eval(nil), 1e-9)
+	})
+}
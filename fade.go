@@ -0,0 +1,172 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"time"
+)
+
+type FadeType string
+
+const (
+	FadeIn  FadeType = "in"
+	FadeOut FadeType = "out"
+)
+
+// FadeFilter fades video in from Color or out to it. Frames before a fade in or after a fade out are set to Color.
+type FadeFilter struct {
+	TimelineAcceptingFilterNode
+	Type FadeType
+
+	// Start and Duration are used if Duration is set, otherwise the fade is given in frames with StartFrame and Frames.
+	Start, Duration    time.Duration
+	StartFrame, Frames int
+
+	// Alpha fades only the alpha channel, if there is one.
+	Alpha bool
+
+	// Color is black if empty.
+	Color string
+}
+
+func (f *FadeFilter) FilterString() string {
+	opts := []string{"type=" + string(f.Type)}
+	if f.Duration > 0 {
+		opts = append(opts, "start_time="+fmtSeconds(f.Start), "duration="+fmtSeconds(f.Duration))
+	} else {
+		opts = append(opts, fmt.Sprintf("start_frame=%v", f.StartFrame), fmt.Sprintf("nb_frames=%v", f.Frames))
+	}
+	if f.Alpha {
+		opts = append(opts, "alpha=1")
+	}
+	if f.Color != "" {
+		opts = append(opts, "color="+f.Color)
+	}
+
+	return filterWithOptions("fade", opts)
+}
+
+func NewFadeFilter(input INode, t FadeType, start, duration time.Duration) *FadeFilter {
+	return &FadeFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		Type:                        t,
+		Start:                       start,
+		Duration:                    duration,
+	}
+}
+
+// NewFrameFadeFilter fades frames between startFrame and startFrame+frames.
+func NewFrameFadeFilter(input INode, t FadeType, startFrame, frames int) *FadeFilter {
+	return &FadeFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		Type:                        t,
+		StartFrame:                  startFrame,
+		Frames:                      frames,
+	}
+}
+
+// FadeCurve is the shape of an audio fade.
+type FadeCurve string
+
+const (
+	CurveTri   FadeCurve = "tri"
+	CurveQSin  FadeCurve = "qsin"
+	CurveHSin  FadeCurve = "hsin"
+	CurveESin  FadeCurve = "esin"
+	CurveLog   FadeCurve = "log"
+	CurveIPar  FadeCurve = "ipar"
+	CurveQua   FadeCurve = "qua"
+	CurveCub   FadeCurve = "cub"
+	CurveSqu   FadeCurve = "squ"
+	CurveCbr   FadeCurve = "cbr"
+	CurvePar   FadeCurve = "par"
+	CurveExp   FadeCurve = "exp"
+	CurveIQSin FadeCurve = "iqsin"
+	CurveIHSin FadeCurve = "ihsin"
+)
+
+// AfadeFilter fades audio in from silence or out to it.
+type AfadeFilter struct {
+	BaseFilterNode
+	Type            FadeType
+	Start, Duration time.Duration
+
+	// Curve is linear if empty.
+	Curve FadeCurve
+}
+
+func (f *AfadeFilter) FilterString() string {
+	opts := []string{"type=" + string(f.Type), "start_time=" + fmtSeconds(f.Start), "duration=" + fmtSeconds(f.Duration)}
+	if f.Curve != "" {
+		opts = append(opts, "curve="+string(f.Curve))
+	}
+
+	return filterWithOptions("afade", opts)
+}
+
+func NewAfadeFilter(input INode, t FadeType, start, duration time.Duration) *AfadeFilter {
+	return &AfadeFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		Type:           t,
+		Start:          start,
+		Duration:       duration,
+	}
+}
+
+func (f *FadeFilter) PropagateInfo(inputs []StreamInfo) StreamInfo  { return inputs[0] }
+func (f *AfadeFilter) PropagateInfo(inputs []StreamInfo) StreamInfo { return inputs[0] }
+
+// AddFades fades video and audio in at the start and out at the end of the clip. Either fade can be zero to skip it
+// and audio can be nil. Duration of the clip should be known, see Info; it is taken from video if audio's is not.
+func AddFades(video, audio INode, fadeIn, fadeOut time.Duration) (INode, INode, error) {
+	d := Info(video).Duration
+	if d == nil {
+		return nil, nil, fmt.Errorf("duration of video is not known")
+	}
+	if fadeIn+fadeOut > *d {
+		return nil, nil, fmt.Errorf("fades of %v and %v are longer than the clip of %v", fadeIn, fadeOut, *d)
+	}
+
+	if fadeIn > 0 {
+		video = NewFadeFilter(video, FadeIn, 0, fadeIn)
+	}
+	if fadeOut > 0 {
+		video = NewFadeFilter(video, FadeOut, *d-fadeOut, fadeOut)
+	}
+	if audio == nil {
+		return video, nil, nil
+	}
+
+	audioDuration := *d
+	if ad := Info(audio).Duration; ad != nil {
+		audioDuration = *ad
+	}
+	if fadeIn > 0 {
+		audio = NewAfadeFilter(audio, FadeIn, 0, fadeIn)
+	}
+	if fadeOut > 0 {
+		audio = NewAfadeFilter(audio, FadeOut, audioDuration-fadeOut, fadeOut)
+	}
+
+	return video, audio, nil
+}
+
+// DipToBlack joins two clips, fading the first one out to black and the second one in from black in the given
+// duration, half of it for each fade. Audio of the clips is faded out and in the same way and joined too, if both
+// firstAudio and secondAudio are given, otherwise returned audio is nil. Duration of first should be known, see Info.
+func DipToBlack(first, firstAudio, second, secondAudio INode, duration time.Duration) (INode, INode, error) {
+	if (firstAudio == nil) != (secondAudio == nil) {
+		return nil, nil, fmt.Errorf("audio of either both clips or none should be given")
+	}
+
+	video, audio, err := AddFades(first, firstAudio, 0, duration/2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	video = NewConcatFilter(video, NewFadeFilter(second, FadeIn, 0, duration/2))
+	if audio == nil {
+		return video, nil, nil
+	}
+
+	return video, NewAudioConcatFilter(audio, NewAfadeFilter(secondAudio, FadeIn, 0, duration/2)), nil
+}
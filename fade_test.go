@@ -0,0 +1,62 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFadeFilters(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+
+	f := NewFadeFilter(i, FadeOut, 8500*time.Millisecond, 1500*time.Millisecond)
+	f.Color = "white"
+	require.Equal(t, "fade=type=out:start_time=8.5:duration=1.5:color=white", f.FilterString())
+
+	f = NewFrameFadeFilter(i, FadeIn, 0, 30)
+	f.Alpha = true
+	require.Equal(t, "fade=type=in:start_frame=0:nb_frames=30:alpha=1", f.FilterString())
+
+	a := NewAfadeFilter(i, FadeIn, 0, time.Second)
+	a.Curve = CurveQSin
+	require.Equal(t, "afade=type=in:start_time=0:duration=1:curve=qsin", a.FilterString())
+}
+
+func TestAddFades(t *testing.T) {
+	i := probedInput(t)
+	video, audio, err := AddFades(NewSelectStreamNode(i, VideoStream), NewSelectStreamNode(i, AudioStream), time.Second, 2*time.Second)
+	require.NoError(t, err)
+
+	cmd := Select([]INode{video, audio}, "out.mp4", nil)
+	require.Contains(t, cmd.FilterComplex(), "[0:v]fade=type=in:start_time=0:duration=1,fade=type=out:start_time=8:duration=2")
+	require.Contains(t, cmd.FilterComplex(), "[0:a]afade=type=in:start_time=0:duration=1,afade=type=out:start_time=8:duration=2")
+
+	_, _, err = AddFades(i, nil, 6*time.Second, 6*time.Second)
+	require.Error(t, err)
+	_, _, err = AddFades(NewInputNode("vid.mp4", nil, nil), nil, time.Second, time.Second)
+	require.Error(t, err)
+}
+
+func TestDipToBlack(t *testing.T) {
+	res, _, err := DipToBlack(probedInput(t), nil, NewInputNode("2.mp4", nil, nil), nil, time.Second)
+	require.NoError(t, err)
+
+	cmd := Select([]INode{res}, "out.mp4", nil)
+	require.Contains(t, cmd.FilterComplex(), "[0:0]fade=type=out:start_time=9.5:duration=0.5")
+	require.Contains(t, cmd.FilterComplex(), "[1:0]fade=type=in:start_time=0:duration=0.5")
+	require.Contains(t, cmd.FilterComplex(), "concat=n=2:v=1:a=0")
+
+	// audio dips to silence with video
+	first, second := probedInput(t), NewInputNode("2.mp4", nil, nil)
+	video, audio, err := DipToBlack(first, NewSelectStreamNode(first, AudioStream), second, NewSelectStreamNode(second, AudioStream), time.Second)
+	require.NoError(t, err)
+
+	cmd = Select([]INode{video, audio}, "out.mp4", nil)
+	require.Contains(t, cmd.FilterComplex(), "[0:a]afade=type=out:start_time=9.5:duration=0.5")
+	require.Contains(t, cmd.FilterComplex(), "[1:a]afade=type=in:start_time=0:duration=0.5")
+	require.Contains(t, cmd.FilterComplex(), "concat=n=2:v=0:a=1")
+
+	_, _, err = DipToBlack(first, NewSelectStreamNode(first, AudioStream), second, nil, time.Second)
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ package ffmpegtree
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type Expression string
@@ -57,6 +58,11 @@ func FilterNodeToStr(node IFilterNode) string {
 		return filterStr
 	}
 
+	// a filter without any option takes enable as its first option
+	if !strings.Contains(filterStr, "=") {
+		return fmt.Sprintf("%v=enable='%v'", filterStr, enableExpr)
+	}
+
 	return fmt.Sprintf("%v:enable='%v'", filterStr, enableExpr)
 }
 
@@ -64,13 +70,27 @@ type ScaleFilterNode struct {
 	BaseFilterNode
 	W, H   int
 	SetSar bool
+
+	// Flags selects the scaling algorithm such as 'lanczos' or 'bicubic'. ffmpeg's default is used if it is empty.
+	Flags string
+
+	// ForceOriginalAspectRatio is either 'decrease' or 'increase' to fit the input inside or around W x H keeping its
+	// aspect ratio, instead of stretching it.
+	ForceOriginalAspectRatio string
 }
 
 func (b *ScaleFilterNode) FilterString() string {
+	scale := fmt.Sprintf("scale=%v:%v", b.W, b.H)
+	if b.ForceOriginalAspectRatio != "" {
+		scale += ":force_original_aspect_ratio=" + b.ForceOriginalAspectRatio
+	}
+	if b.Flags != "" {
+		scale += ":flags=" + b.Flags
+	}
 	if b.SetSar {
-		return fmt.Sprintf("scale=%v:%v,setsar=1:1", b.W, b.H)
+		return scale + ",setsar=1:1"
 	}
-	return fmt.Sprintf("scale=%v:%v", b.W, b.H)
+	return scale
 }
 
 func NewScaleFilterNode(input INode, w, h int, setsar bool) *ScaleFilterNode {
@@ -83,7 +103,7 @@ func NewScaleFilterNode(input INode, w, h int, setsar bool) *ScaleFilterNode {
 }
 
 type OverlayIntoMiddleFilterNode struct {
-	BaseFilterNode
+	TimelineAcceptingFilterNode
 }
 
 func (n *OverlayIntoMiddleFilterNode) FilterString() string {
@@ -92,12 +112,12 @@ func (n *OverlayIntoMiddleFilterNode) FilterString() string {
 
 func NewOverlayIntoMiddleFilterNode(input1, input2 INode) *OverlayIntoMiddleFilterNode {
 	return &OverlayIntoMiddleFilterNode{
-		BaseFilterNode: *NewBaseFilterNode([]INode{input1, input2}, randStr()),
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input1, input2}, randStr()),
 	}
 }
 
 type OverlayFilterNode struct {
-	BaseFilterNode
+	TimelineAcceptingFilterNode
 	x, y Expression
 }
 
@@ -107,9 +127,9 @@ func (n *OverlayFilterNode) FilterString() string {
 
 func NewOverlayFilterNode(input1, input2 INode, x, y Expression) *OverlayFilterNode {
 	return &OverlayFilterNode{
-		BaseFilterNode: *NewBaseFilterNode([]INode{input1, input2}, randStr()),
-		x:              x,
-		y:              y,
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input1, input2}, randStr()),
+		x:                           x,
+		y:                           y,
 	}
 }
 
@@ -136,8 +156,37 @@ func NewCropFilter(input INode, w, h int, x, y Expression) *CropFilter {
 	}
 }
 
-type ChromaFilterNode struct {
+// PadFilter places the input on a W x H frame at X, Y and fills the rest with Color, which is black if it is empty.
+type PadFilter struct {
 	BaseFilterNode
+	W, H int
+	X, Y Expression
+
+	Color string
+}
+
+func (f *PadFilter) FilterString() string {
+	res := fmt.Sprintf("pad=%v:%v:%v:%v", f.W, f.H, f.X, f.Y)
+	if f.Color != "" {
+		res += ":color=" + f.Color
+	}
+
+	return res
+}
+
+// NewPadFilter places input in the middle of a w x h frame.
+func NewPadFilter(input INode, w, h int) *PadFilter {
+	return &PadFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		W:              w,
+		H:              h,
+		X:              "(ow-iw)/2",
+		Y:              "(oh-ih)/2",
+	}
+}
+
+type ChromaFilterNode struct {
+	TimelineAcceptingFilterNode
 	Color string
 	Sim   float32
 }
@@ -148,9 +197,9 @@ func (n *ChromaFilterNode) FilterString() string {
 
 func NewChromaFilterNode(input INode, color string, sim float32) *ChromaFilterNode {
 	return &ChromaFilterNode{
-		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
-		Color:          color,
-		Sim:            sim,
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		Color:                       color,
+		Sim:                         sim,
 	}
 }
 
@@ -199,7 +248,7 @@ func NewDrawBoxFilter(input INode, x, y, w, h int, color, t string) *DrawBoxFilt
 type BoxBlurFilter struct {
 	lumaRadius, chromaRadius string
 	lumaPower                int
-	BaseFilterNode
+	TimelineAcceptingFilterNode
 }
 
 func (f *BoxBlurFilter) FilterString() string {
@@ -208,20 +257,47 @@ func (f *BoxBlurFilter) FilterString() string {
 
 func NewBoxBlurFilter(input INode, lumaRadius string, chromaRadius string, lumaPower int) *BoxBlurFilter {
 	return &BoxBlurFilter{
-		lumaRadius:     lumaRadius,
-		chromaRadius:   chromaRadius,
-		lumaPower:      lumaPower,
-		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		lumaRadius:                  lumaRadius,
+		chromaRadius:                chromaRadius,
+		lumaPower:                   lumaPower,
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
 	}
 }
 
 type CurvesFilter struct {
 	TimelineAcceptingFilterNode
 	preset string
+
+	// Master, Red, Green and Blue are control points of custom curves. Master is applied after color components.
+	// They override the preset for their components.
+	Master, Red, Green, Blue []CurvePoint
+}
+
+// CurvePoint maps input level X to output level Y, both between 0 and 1.
+type CurvePoint struct {
+	X, Y float64
 }
 
 func (f *CurvesFilter) FilterString() string {
-	return fmt.Sprintf("curves=preset=%v", f.preset)
+	opts := make([]string, 0)
+	if f.preset != "" {
+		opts = append(opts, "preset="+f.preset)
+	}
+	for _, c := range []struct {
+		name   string
+		points []CurvePoint
+	}{{"master", f.Master}, {"red", f.Red}, {"green", f.Green}, {"blue", f.Blue}} {
+		if len(c.points) == 0 {
+			continue
+		}
+		points := make([]string, 0, len(c.points))
+		for _, p := range c.points {
+			points = append(points, fmtFloat(p.X)+"/"+fmtFloat(p.Y))
+		}
+		opts = append(opts, fmt.Sprintf("%v='%v'", c.name, strings.Join(points, " ")))
+	}
+
+	return filterWithOptions("curves", opts)
 }
 
 func NewCurvesFilter(input INode, preset string) *CurvesFilter {
@@ -231,8 +307,17 @@ func NewCurvesFilter(input INode, preset string) *CurvesFilter {
 	}
 }
 
+// NewCustomCurvesFilter applies curves given with control points of master, which can be followed by red, green and
+// blue control points with fields of the filter.
+func NewCustomCurvesFilter(input INode, master ...CurvePoint) *CurvesFilter {
+	return &CurvesFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		Master:                      master,
+	}
+}
+
 type RotateFilter struct {
-	BaseFilterNode
+	TimelineAcceptingFilterNode
 	rotateExpr string
 }
 
@@ -242,8 +327,8 @@ func (f *RotateFilter) FilterString() string {
 
 func NewRotateFilter(input INode, rotateExpr string) *RotateFilter {
 	return &RotateFilter{
-		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
-		rotateExpr:     rotateExpr,
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{input}, randStr()),
+		rotateExpr:                  rotateExpr,
 	}
 }
 
@@ -267,6 +352,41 @@ type DrawTextFilter struct {
 	TimelineAcceptingFilterNode
 	x, y, text, fontColor string
 	fontSize, boxHeight   int
+
+	// expand enables expansion of sequences such as '%{pts}' in text
+	expand bool
+
+	// textFile is read instead of text if it is set
+	textFile string
+
+	// Reload reads the text file again before every frame, so that it can be updated while ffmpeg is running.
+	Reload bool
+
+	// FontFile is the path of the font. Font is a fontconfig pattern such as 'Arial:style=Bold', which is used if
+	// ffmpeg is built with fontconfig.
+	FontFile, Font string
+
+	// Box draws a box of BoxColor around the text, extended by BoxBorderW pixels.
+	Box        bool
+	BoxColor   string
+	BoxBorderW int
+
+	// BorderW draws an outline of BorderColor around the glyphs.
+	BorderW     int
+	BorderColor string
+
+	// ShadowX and ShadowY are the offset of the shadow of ShadowColor. Shadow is not drawn if both are zero.
+	ShadowColor      string
+	ShadowX, ShadowY int
+
+	// Alpha is the opacity between 0 and 1, which can change over time such as for fading the text in.
+	Alpha Expr
+
+	// LineSpacing is the space between lines of a multi-line text, in pixels.
+	LineSpacing int
+
+	// TextAlign aligns lines of a multi-line text, such as 'center' or 'right'. It needs ffmpeg 6.1 or later.
+	TextAlign string
 }
 
 func (f *DrawTextFilter) FilterString() string {
@@ -280,7 +400,65 @@ func (f *DrawTextFilter) FilterString() string {
 		y = fmt.Sprintf("%v", f.y)
 	}
 
-	return fmt.Sprintf("drawtext=expansion=none:text='%v':fontcolor=%v:fontsize=%v:x=%v:y=%v", escapeText(f.text), color, f.fontSize, f.x, y)
+	res := "drawtext="
+	if !f.expand {
+		res += "expansion=none:"
+	}
+	switch {
+	case f.textFile != "":
+		res += "textfile=" + escapeFilterArg(f.textFile)
+		if f.Reload {
+			res += ":reload=1"
+		}
+	case f.expand:
+		res += fmt.Sprintf("text='%v'", escapeExpandedText(f.text))
+	default:
+		res += fmt.Sprintf("text='%v'", escapeText(f.text))
+	}
+	res += fmt.Sprintf(":fontcolor=%v:fontsize=%v:x=%v:y=%v", color, f.fontSize, f.x, y)
+
+	opts := make([]string, 0)
+	if f.FontFile != "" {
+		opts = append(opts, "fontfile="+escapeFilterArg(f.FontFile))
+	}
+	if f.Font != "" {
+		opts = append(opts, "font="+escapeFilterArg(f.Font))
+	}
+	if f.Box {
+		opts = append(opts, "box=1")
+		if f.BoxColor != "" {
+			opts = append(opts, "boxcolor="+f.BoxColor)
+		}
+		if f.BoxBorderW > 0 {
+			opts = append(opts, fmt.Sprintf("boxborderw=%v", f.BoxBorderW))
+		}
+	}
+	if f.BorderW > 0 {
+		opts = append(opts, fmt.Sprintf("borderw=%v", f.BorderW))
+		if f.BorderColor != "" {
+			opts = append(opts, "bordercolor="+f.BorderColor)
+		}
+	}
+	if f.ShadowX != 0 || f.ShadowY != 0 {
+		if f.ShadowColor != "" {
+			opts = append(opts, "shadowcolor="+f.ShadowColor)
+		}
+		opts = append(opts, fmt.Sprintf("shadowx=%v", f.ShadowX), fmt.Sprintf("shadowy=%v", f.ShadowY))
+	}
+	if f.Alpha != nil {
+		opts = append(opts, "alpha="+Quoted(f.Alpha))
+	}
+	if f.LineSpacing != 0 {
+		opts = append(opts, fmt.Sprintf("line_spacing=%v", f.LineSpacing))
+	}
+	if f.TextAlign != "" {
+		opts = append(opts, "text_align="+f.TextAlign)
+	}
+	if len(opts) == 0 {
+		return res
+	}
+
+	return res + ":" + strings.Join(opts, ":")
 }
 
 func NewDrawTextFilter(input INode, text, fontColor, x, y string, boxHeight, fontSize int) *DrawTextFilter {
@@ -295,6 +473,56 @@ func NewDrawTextFilter(input INode, text, fontColor, x, y string, boxHeight, fon
 	}
 }
 
+// NewDynamicTextFilter draws a text in which sequences such as '%{pts}' are expanded for every frame. template can be
+// built with PtsText, LocalTimeText, FrameNumberText and LiteralText.
+func NewDynamicTextFilter(input INode, template, fontColor, x, y string, fontSize int) *DrawTextFilter {
+	f := NewDrawTextFilter(input, template, fontColor, x, y, 0, fontSize)
+	f.expand = true
+	return f
+}
+
+// NewTextFileFilter draws the content of the file at path, which is not expanded.
+func NewTextFileFilter(input INode, path, fontColor, x, y string, fontSize int) *DrawTextFilter {
+	f := NewDrawTextFilter(input, "", fontColor, x, y, 0, fontSize)
+	f.textFile = path
+	return f
+}
+
+// NewTimestampFilter draws the time of every frame in 'HH:MM:SS.mmm' format.
+func NewTimestampFilter(input INode, fontColor, x, y string, fontSize int) *DrawTextFilter {
+	return NewDynamicTextFilter(input, PtsText("hms"), fontColor, x, y, fontSize)
+}
+
+// PtsText expands to the presentation time of the frame in format, which is one of 'flt', 'hms' and 'localtime'.
+func PtsText(format string) string {
+	return expansionSequence("pts", format)
+}
+
+// LocalTimeText expands to the local time in strftime format such as '%H:%M:%S'.
+func LocalTimeText(format string) string {
+	return expansionSequence("localtime", format)
+}
+
+// FrameNumberText expands to the number of the frame, starting from 0.
+func FrameNumberText() string {
+	return expansionSequence("n")
+}
+
+// LiteralText escapes text to be drawn as it is in a template of NewDynamicTextFilter.
+func LiteralText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`).Replace(text)
+}
+
+// expansionSequence returns a sequence such as '%{pts:hms}', escaping its arguments.
+func expansionSequence(name string, args ...string) string {
+	parts := []string{name}
+	for _, a := range args {
+		parts = append(parts, strings.NewReplacer(`\`, `\\`, ":", `\:`, "}", `\}`).Replace(a))
+	}
+
+	return "%{" + strings.Join(parts, ":") + "}"
+}
+
 type FpsFilter struct {
 	BaseFilterNode
 	fps int
@@ -312,18 +540,30 @@ func NewFpsFilterNode(inp INode, fps int) *FpsFilter {
 }
 
 type VolumeFilter struct {
-	BaseFilterNode
-	vol float32
+	TimelineAcceptingFilterNode
+	vol     float32
+	volExpr Expr
 }
 
 func (s *VolumeFilter) FilterString() string {
+	if s.volExpr != nil {
+		return fmt.Sprintf(`volume=volume=%v:eval=frame`, Quoted(s.volExpr))
+	}
 	return fmt.Sprintf(`volume=%.2f`, s.vol)
 }
 
 func NewVolumeFilter(inp INode, volume float32) *VolumeFilter {
 	return &VolumeFilter{
-		BaseFilterNode: *NewBaseFilterNode([]INode{inp}, randStr()),
-		vol:            volume,
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{inp}, randStr()),
+		vol:                         volume,
+	}
+}
+
+// NewVolumeExprFilter changes volume by an expression which is evaluated for each frame, such as Keyframes.Expr.
+func NewVolumeExprFilter(inp INode, volume Expr) *VolumeFilter {
+	return &VolumeFilter{
+		TimelineAcceptingFilterNode: *NewTimelineAcceptingFilterNode([]INode{inp}, randStr()),
+		volExpr:                     volume,
 	}
 }
 
@@ -357,3 +597,96 @@ func NewAformatFilter(inp INode) *AformatFilter {
 		BaseFilterNode: *NewBaseFilterNode([]INode{inp}, randStr()),
 	}
 }
+
+type ConcatFilter struct {
+	BaseFilterNode
+	audio bool
+}
+
+func (f *ConcatFilter) FilterString() string {
+	if f.audio {
+		return fmt.Sprintf("concat=n=%v:v=0:a=1", len(f.inputs))
+	}
+	return fmt.Sprintf("concat=n=%v:v=1:a=0", len(f.inputs))
+}
+
+// NewConcatFilter joins video segments one after another.
+func NewConcatFilter(inputs ...INode) *ConcatFilter {
+	return &ConcatFilter{
+		BaseFilterNode: *NewBaseFilterNode(inputs, randStr()),
+	}
+}
+
+// NewAudioConcatFilter joins audio segments one after another.
+func NewAudioConcatFilter(inputs ...INode) *ConcatFilter {
+	return &ConcatFilter{
+		BaseFilterNode: *NewBaseFilterNode(inputs, randStr()),
+		audio:          true,
+	}
+}
+
+type TrimFilter struct {
+	BaseFilterNode
+	Start, Duration *time.Duration
+	audio           bool
+}
+
+func (f *TrimFilter) FilterString() string {
+	name := "trim"
+	if f.audio {
+		name = "atrim"
+	}
+
+	opts := make([]string, 0)
+	if f.Start != nil {
+		opts = append(opts, "start="+fmtSeconds(*f.Start))
+	}
+	if f.Duration != nil {
+		opts = append(opts, "duration="+fmtSeconds(*f.Duration))
+	}
+	if len(opts) == 0 {
+		return name
+	}
+
+	return name + "=" + strings.Join(opts, ":")
+}
+
+// NewTrimFilter keeps the part of a video stream starting from start for the given duration. Either of them can be nil.
+func NewTrimFilter(input INode, start, duration *time.Duration) *TrimFilter {
+	return &TrimFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		Start:          start,
+		Duration:       duration,
+	}
+}
+
+// NewAtrimFilter is the audio counterpart of NewTrimFilter.
+func NewAtrimFilter(input INode, start, duration *time.Duration) *TrimFilter {
+	return &TrimFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		Start:          start,
+		Duration:       duration,
+		audio:          true,
+	}
+}
+
+type XfadeFilter struct {
+	BaseFilterNode
+	Transition       string
+	Duration, Offset time.Duration
+}
+
+func (f *XfadeFilter) FilterString() string {
+	return fmt.Sprintf("xfade=transition=%v:duration=%v:offset=%v", f.Transition, fmtSeconds(f.Duration), fmtSeconds(f.Offset))
+}
+
+// NewXfadeFilter crossfades from the first input to the second one. Transition starts at offset, relative to the first
+// input, and lasts for duration.
+func NewXfadeFilter(from, to INode, transition string, duration, offset time.Duration) *XfadeFilter {
+	return &XfadeFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{from, to}, randStr()),
+		Transition:     transition,
+		Duration:       duration,
+		Offset:         offset,
+	}
+}
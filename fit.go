@@ -0,0 +1,90 @@
+package ffmpegtree
+
+import "fmt"
+
+// FitMode decides how a video is fitted into a frame of another aspect ratio.
+type FitMode int
+
+const (
+	// Contain scales the video to fit inside the frame and pads the rest, which letterboxes or pillarboxes it.
+	Contain FitMode = iota
+
+	// Cover scales the video to cover the frame and crops what overflows.
+	Cover
+
+	// Stretch scales the video to the frame ignoring its aspect ratio.
+	Stretch
+
+	// BlurredBackground places the contained video on a blurred copy of itself which covers the frame, instead of
+	// padding it with a color.
+	BlurredBackground
+)
+
+// Fit scales node to a frame of w x h with the given mode. Output has square pixels in every mode, so that it is
+// displayed as w x h.
+func Fit(node INode, w, h int, mode FitMode) (INode, error) {
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("frame size should be positive")
+	}
+
+	if mode == Stretch {
+		return NewScaleFilterNode(node, w, h, true), nil
+	}
+
+	// force_original_aspect_ratio keeps the ratio of the stored size, which is not the displayed one if pixels are not
+	// square, hence they are made square first
+	square := NewSquarePixelsFilter(node)
+	switch mode {
+	case Contain:
+		return NewPadFilter(fitScale(square, w, h, "decrease"), w, h), nil
+	case Cover:
+		return coverCrop(square, w, h), nil
+	case BlurredBackground:
+		// node is used by both the background and the foreground, hence it is split by the executor
+		bg := NewBoxBlurFilter(coverCrop(square, w, h), "20", "10", 2)
+		return NewOverlayIntoMiddleFilterNode(bg, fitScale(square, w, h, "decrease")), nil
+	}
+
+	return nil, fmt.Errorf("unknown fit mode: %v", mode)
+}
+
+// SquarePixelsFilter scales the width of the input so that its pixels are square, keeping its display aspect ratio.
+type SquarePixelsFilter struct {
+	BaseFilterNode
+}
+
+func (f *SquarePixelsFilter) FilterString() string {
+	return "scale=iw*sar:ih,setsar=1"
+}
+
+func (f *SquarePixelsFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	if res.Width != nil && res.SAR != nil {
+		// scale truncates the evaluated width
+		res.Width = intPtr(int(float64(*res.Width) * *res.SAR))
+	} else {
+		res.Width = nil
+	}
+	res.SAR = float64Ptr(1)
+
+	return res
+}
+
+func NewSquarePixelsFilter(input INode) *SquarePixelsFilter {
+	return &SquarePixelsFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+	}
+}
+
+// fitScale scales node keeping its aspect ratio so that it fits inside w x h or covers it, depending on
+// forceOriginalAspectRatio.
+func fitScale(node INode, w, h int, forceOriginalAspectRatio string) *ScaleFilterNode {
+	s := NewScaleFilterNode(node, w, h, true)
+	s.ForceOriginalAspectRatio = forceOriginalAspectRatio
+	return s
+}
+
+// coverCrop scales node to cover w x h and crops the middle of it.
+func coverCrop(node INode, w, h int) *CropFilter {
+	return NewCropFilter(fitScale(node, w, h, "increase"), w, h, "(in_w-out_w)/2", "(in_h-out_h)/2")
+}
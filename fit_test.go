@@ -0,0 +1,74 @@
+package ffmpegtree
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFit(t *testing.T) {
+	t.Run("contain", func(t *testing.T) {
+		node, err := Fit(probedInput(t), 1080, 1920, Contain)
+		require.NoError(t, err)
+		cmd := Select([]INode{node}, "out.mp4", nil)
+		require.Equal(t, "[0:0]scale=iw*sar:ih,setsar=1,scale=1080:1920:force_original_aspect_ratio=decrease,setsar=1:1,pad=1080:1920:'(ow-iw)/2':'(oh-ih)/2'", cmd.FilterComplex())
+
+		info := Info(node)
+		require.Equal(t, 1080, *info.Width)
+		require.Equal(t, 1920, *info.Height)
+		require.Equal(t, 1.0, *info.SAR)
+
+		scaled := NewScaleFilterNode(probedInput(t), 640, 640, true)
+		scaled.ForceOriginalAspectRatio = "decrease"
+		require.Equal(t, 360, *Info(scaled).Height)
+	})
+
+	t.Run("cover", func(t *testing.T) {
+		node, err := Fit(probedInput(t), 1080, 1920, Cover)
+		require.NoError(t, err)
+		cmd := Select([]INode{node}, "out.mp4", nil)
+		require.Equal(t, "[0:0]scale=iw*sar:ih,setsar=1,scale=1080:1920:force_original_aspect_ratio=increase,setsar=1:1,crop=1080:1920:x='(in_w-out_w)/2':y='(in_h-out_h)/2'", cmd.FilterComplex())
+
+		scaled := NewScaleFilterNode(probedInput(t), 1080, 1920, true)
+		scaled.ForceOriginalAspectRatio = "increase"
+		require.Equal(t, 3413, *Info(scaled).Width)
+	})
+
+	t.Run("stretch", func(t *testing.T) {
+		node, err := Fit(probedInput(t), 1080, 1920, Stretch)
+		require.NoError(t, err)
+		require.Equal(t, "scale=1080:1920,setsar=1:1", node.(IFilterNode).FilterString())
+	})
+
+	t.Run("blurred background", func(t *testing.T) {
+		node, err := Fit(probedInput(t), 1080, 1920, BlurredBackground)
+		require.NoError(t, err)
+		cmd := Select([]INode{node}, "out.mp4", nil)
+
+		reg := regexp.MustCompile(`^\[0:0]scale=iw\*sar:ih,setsar=1,split(?P<s1>\[.*])(?P<s2>\[.*]);(?P<s2_2>\[.*])scale=1080:1920:force_original_aspect_ratio=decrease,setsar=1:1(?P<fg>\[.*]);(?P<s1_2>\[.*])scale=1080:1920:force_original_aspect_ratio=increase,setsar=1:1,crop=1080:1920:x='\(in_w-out_w\)/2':y='\(in_h-out_h\)/2',boxblur=luma_radius=20:chroma_radius=10:luma_power=2(?P<bg>\[.*]);(?P<bg_2>\[.*])(?P<fg_2>\[.*])overlay=main_w/2-overlay_w/2:main_h/2-overlay_h/2$`)
+		require.Regexp(t, reg, cmd.FilterComplex())
+		params := getParams(reg, cmd.FilterComplex())
+		require.Equal(t, params["s1"], params["s1_2"])
+		require.Equal(t, params["s2"], params["s2_2"])
+		require.Equal(t, params["fg"], params["fg_2"])
+		require.Equal(t, params["bg"], params["bg_2"])
+	})
+
+	t.Run("anamorphic", func(t *testing.T) {
+		// 720x576 with 16:15 pixels is displayed as 768x576
+		i := probedInput(t)
+		i.Metadata.Streams[0].Width, i.Metadata.Streams[0].Height, i.Metadata.Streams[0].SampleAspectRatio = 720, 576, "16:15"
+
+		node, err := Fit(i, 1280, 720, Contain)
+		require.NoError(t, err)
+		fitted := node.GetInputs()[0]
+		require.Equal(t, 768, *Info(fitted.GetInputs()[0]).Width)
+		require.Equal(t, 960, *Info(fitted).Width)
+		require.Equal(t, 720, *Info(fitted).Height)
+		require.Equal(t, 1.0, *Info(fitted).SAR)
+	})
+
+	_, err := Fit(probedInput(t), 0, 1920, Contain)
+	require.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PaletteGenFilter generates a palette of at most 256 colors from the whole video, to be used by PaletteUseFilter.
+type PaletteGenFilter struct {
+	BaseFilterNode
+
+	// MaxColors is the number of colors in the palette, which is 256 if it is zero.
+	MaxColors int
+
+	// StatsMode is one of 'full', 'diff' or 'single'. 'diff' favors moving parts of the video and 'single' generates a
+	// palette for every frame, which requires PaletteUseFilter.New to be set.
+	StatsMode string
+
+	// ReserveTransparent reserves a palette entry for transparency.
+	ReserveTransparent bool
+}
+
+func (f *PaletteGenFilter) FilterString() string {
+	opts := make([]string, 0)
+	if f.MaxColors > 0 {
+		opts = append(opts, fmt.Sprintf("max_colors=%v", f.MaxColors))
+	}
+	if f.StatsMode != "" {
+		opts = append(opts, "stats_mode="+f.StatsMode)
+	}
+	if f.ReserveTransparent {
+		opts = append(opts, "reserve_transparent=1")
+	}
+
+	return filterWithOptions("palettegen", opts)
+}
+
+func NewPaletteGenFilter(input INode) *PaletteGenFilter {
+	return &PaletteGenFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+	}
+}
+
+// PaletteUseFilter reduces colors of input to palette, which is generated by PaletteGenFilter.
+type PaletteUseFilter struct {
+	BaseFilterNode
+
+	// Dither is one of 'bayer', 'heckbert', 'floyd_steinberg', 'sierra2', 'sierra2_4a' and 'none'.
+	Dither string
+
+	// BayerScale is between 0 and 5 and only used by bayer dithering. Lower values give a more visible pattern with
+	// less banding.
+	BayerScale *int
+
+	// DiffMode 'rectangle' only processes the changed area of frames, which makes it faster and reduces noise.
+	DiffMode string
+
+	// New uses a new palette for every frame, as generated by palettegen with 'single' stats mode.
+	New bool
+}
+
+func (f *PaletteUseFilter) FilterString() string {
+	opts := make([]string, 0)
+	if f.Dither != "" {
+		opts = append(opts, "dither="+f.Dither)
+	}
+	if f.BayerScale != nil {
+		opts = append(opts, fmt.Sprintf("bayer_scale=%v", *f.BayerScale))
+	}
+	if f.DiffMode != "" {
+		opts = append(opts, "diff_mode="+f.DiffMode)
+	}
+	if f.New {
+		opts = append(opts, "new=1")
+	}
+
+	return filterWithOptions("paletteuse", opts)
+}
+
+func NewPaletteUseFilter(input, palette INode) *PaletteUseFilter {
+	return &PaletteUseFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input, palette}, randStr()),
+	}
+}
+
+func (f *PaletteGenFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	return StreamInfo{Width: intPtr(16), Height: intPtr(16)}
+}
+
+func (f *PaletteUseFilter) PropagateInfo(inputs []StreamInfo) StreamInfo { return inputs[0] }
+
+// ToGIF returns the canonical graph of a high quality gif, which generates a palette from the video and then uses it
+// to reduce colors of the same video. Video is converted to fps and scaled to width keeping its aspect ratio. The
+// result should be written to a file with '.gif' extension.
+func ToGIF(node INode, fps, width int) *PaletteUseFilter {
+	scaled := NewScaleFilterNode(NewFpsFilterNode(node, fps), width, -1, false)
+	scaled.Flags = "lanczos"
+
+	// scaled is used by both filters, hence it is split by the executor
+	palette := NewPaletteGenFilter(scaled)
+	palette.StatsMode = "diff"
+
+	res := NewPaletteUseFilter(scaled, palette)
+	res.Dither = "bayer"
+	res.BayerScale = intPtr(5)
+	res.DiffMode = "rectangle"
+
+	return res
+}
+
+// WebPOutput implements IOutput
+var _ IOutput = &WebPOutput{}
+
+// WebPOutput writes video as an animated webp, which does not need a palette unlike gif.
+type WebPOutput struct {
+	Name string
+
+	// Loop is the number of times animation is played, 0 means forever.
+	Loop int
+
+	Lossless bool
+
+	// Quality is between 0 and 100. It is the compression effort if Lossless is set.
+	Quality *int
+
+	// Preset is one of 'default', 'picture', 'photo', 'drawing', 'icon' and 'text'.
+	Preset string
+}
+
+func (w *WebPOutput) ToString() []string {
+	res := []string{"-c:v", "libwebp_anim"}
+	if w.Lossless {
+		res = append(res, "-lossless", "1")
+	}
+	if w.Quality != nil {
+		res = append(res, "-quality", fmt.Sprintf("%v", *w.Quality))
+	}
+	if w.Preset != "" {
+		res = append(res, "-preset", w.Preset)
+	}
+
+	return append(res, "-loop", fmt.Sprintf("%v", w.Loop), "-f", "webp", w.Name)
+}
+
+// filterWithOptions returns name followed by options separated with ':', if there is any.
+func filterWithOptions(name string, opts []string) string {
+	if len(opts) == 0 {
+		return name
+	}
+
+	return name + "=" + strings.Join(opts, ":")
+}
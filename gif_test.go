@@ -0,0 +1,30 @@
+package ffmpegtree
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToGIF(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+	gif := ToGIF(i, 10, 320)
+
+	cmd := Select([]INode{gif}, "out.gif", nil)
+	reg := regexp.MustCompile(`^\[0:0]fps=10,scale=320:-1:flags=lanczos,split(?P<s1>\[.*])(?P<s2>\[.*]);(?P<s2_2>\[.*])palettegen=stats_mode=diff(?P<p>\[.*]);(?P<s1_2>\[.*])(?P<p_2>\[.*])paletteuse=dither=bayer:bayer_scale=5:diff_mode=rectangle$`)
+	require.Regexp(t, reg, cmd.FilterComplex())
+	params := getParams(reg, cmd.FilterComplex())
+	require.Equal(t, params["s1"], params["s1_2"])
+	require.Equal(t, params["s2"], params["s2_2"])
+	require.Equal(t, params["p"], params["p_2"])
+}
+
+func TestWebPOutput(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+	exec := NewFfmpegExecutor(nil, "", nil)
+	exec.SetOutput(&WebPOutput{Name: "out.webp", Quality: intPtr(80)})
+
+	cmd := exec.ToFfmpeg(NewFpsFilterNode(i, 15))
+	require.Equal(t, []string{"-i", "vid.mp4", "-filter_complex", "[0:0]fps=15", "-c:v", "libwebp_anim", "-quality", "80", "-loop", "0", "-f", "webp", "out.webp"}, []string(cmd))
+}
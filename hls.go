@@ -0,0 +1,157 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HLSOutput implements IOutput
+var _ IOutput = &HLSOutput{}
+
+// HLSOutput writes streams as an HLS playlist with '-f hls'.
+type HLSOutput struct {
+	// PlaylistName is the name of the media playlist. It should contain '%v' if there is more than one variant stream.
+	PlaylistName string
+
+	SegmentDuration time.Duration
+
+	// PlaylistType is either 'vod' or 'event', or empty for a live playlist.
+	PlaylistType string
+
+	// SegmentFilename is the name pattern of segments such as 'stream_%v_%03d.ts'.
+	SegmentFilename string
+
+	IndependentSegments bool
+
+	// Flags are other hls_flags such as 'delete_segments'.
+	Flags []string
+
+	// MasterPlaylistName is the name of the master playlist, which is only written if it is set.
+	MasterPlaylistName string
+
+	// VarStreamMap groups mapped streams into variant streams such as 'v:0,a:0 v:1,a:1'.
+	VarStreamMap string
+}
+
+func (h *HLSOutput) ToString() []string {
+	res := []string{"-f", "hls"}
+	if h.SegmentDuration > 0 {
+		res = append(res, "-hls_time", fmtSeconds(h.SegmentDuration))
+	}
+	if h.PlaylistType != "" {
+		res = append(res, "-hls_playlist_type", h.PlaylistType)
+	}
+
+	flags := make([]string, 0, len(h.Flags)+1)
+	if h.IndependentSegments {
+		flags = append(flags, "independent_segments")
+	}
+	flags = append(flags, h.Flags...)
+	if len(flags) > 0 {
+		res = append(res, "-hls_flags", strings.Join(flags, "+"))
+	}
+
+	if h.SegmentFilename != "" {
+		res = append(res, "-hls_segment_filename", h.SegmentFilename)
+	}
+	if h.MasterPlaylistName != "" {
+		res = append(res, "-master_pl_name", h.MasterPlaylistName)
+	}
+	if h.VarStreamMap != "" {
+		res = append(res, "-var_stream_map", h.VarStreamMap)
+	}
+
+	return append(res, h.PlaylistName)
+}
+
+// Rendition is a variant of the video in an adaptive bitrate ladder. Height or Width can be -2 to keep aspect ratio.
+type Rendition struct {
+	Width, Height int
+
+	// VideoBitrate, MaxRate and BufSize are in ffmpeg's syntax such as '5M' or '800k'. MaxRate and BufSize are
+	// optional.
+	VideoBitrate, MaxRate, BufSize string
+}
+
+// HLSLadder encodes a video into several renditions in one command and writes them as HLS variant streams with a
+// master playlist. Audio, if there is any, is encoded once and shared by all variants as an audio group.
+type HLSLadder struct {
+	Output     HLSOutput
+	Renditions []Rendition
+
+	// VideoCodec and AudioCodec default to libx264 and aac.
+	VideoCodec, AudioCodec string
+
+	AudioBitrate string
+}
+
+// Build returns an executor which writes the ladder and output nodes which should be passed to its ToFfmpeg. audio
+// can be nil.
+func (l *HLSLadder) Build(video, audio INode) (*FFmpegExecutor, []INode, error) {
+	if len(l.Renditions) == 0 {
+		return nil, nil, fmt.Errorf("ladder needs at least one rendition")
+	}
+	if l.Output.SegmentDuration <= 0 {
+		return nil, nil, fmt.Errorf("segment duration should be positive")
+	}
+
+	videoCodec, audioCodec := l.VideoCodec, l.AudioCodec
+	if videoCodec == "" {
+		videoCodec = "libx264"
+	}
+	if audioCodec == "" {
+		audioCodec = "aac"
+	}
+
+	nodes := make([]INode, 0, len(l.Renditions)+1)
+	maps := make([]IMap, 0, len(l.Renditions)+1)
+	variants := make([]string, 0, len(l.Renditions)+1)
+
+	// keyframes are forced at segment boundaries so that segments of every rendition are aligned and independent
+	opts := []string{
+		"-c:v", videoCodec,
+		"-force_key_frames", forceKeyFramesExpr(l.Output.SegmentDuration),
+	}
+	for i, r := range l.Renditions {
+		scaled := NewScaleFilterNode(video, r.Width, r.Height, true)
+		nodes = append(nodes, scaled)
+		maps = append(maps, NewMap(scaled))
+		opts = append(opts, rateControlOptions("v", i, r.VideoBitrate, r.MaxRate, r.BufSize)...)
+
+		variant := fmt.Sprintf("v:%v", i)
+		if audio != nil {
+			variant += ",agroup:audio"
+		}
+		variants = append(variants, variant)
+	}
+
+	if audio != nil {
+		if _, ok := audio.(IFilterNode); ok {
+			nodes = append(nodes, audio)
+		}
+		maps = append(maps, NewMap(audio))
+		opts = append(opts, "-c:a", audioCodec)
+		if l.AudioBitrate != "" {
+			opts = append(opts, "-b:a", l.AudioBitrate)
+		}
+		variants = append(variants, "a:0,agroup:audio")
+	}
+
+	// every rendition is scaled from the same video
+	insertSplitAcross(nodes)
+
+	out := l.Output
+	out.VarStreamMap = strings.Join(variants, " ")
+	if out.PlaylistName == "" {
+		out.PlaylistName = "stream_%v.m3u8"
+	}
+	if out.MasterPlaylistName == "" {
+		out.MasterPlaylistName = "master.m3u8"
+	}
+
+	exec := NewFfmpegExecutor(maps, "", opts)
+	exec.SetOutput(&out)
+
+	return exec, nodes, nil
+}
@@ -0,0 +1,52 @@
+package ffmpegtree
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHLSLadder(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+	video := NewCurvesFilter(i, "vintage")
+	audio := NewSelectStreamNode(i, AudioStream)
+
+	ladder := &HLSLadder{
+		Output: HLSOutput{
+			SegmentDuration:     4 * time.Second,
+			PlaylistType:        "vod",
+			SegmentFilename:     "stream_%v_%03d.ts",
+			IndependentSegments: true,
+		},
+		Renditions: []Rendition{
+			{Width: 1920, Height: 1080, VideoBitrate: "5M", MaxRate: "5350k", BufSize: "7500k"},
+			{Width: 1280, Height: 720, VideoBitrate: "2800k"},
+		},
+		AudioBitrate: "128k",
+	}
+	exec, nodes, err := ladder.Build(video, audio)
+	require.NoError(t, err)
+	cmd := exec.ToFfmpeg(nodes...)
+
+	reg := regexp.MustCompile(`^\[0:0]curves=preset=vintage,split(?P<s1>\[.*])(?P<s2>\[.*]);(?P<s2_2>\[.*])scale=1280:720,setsar=1:1(?P<s3>\[.*]);(?P<s1_2>\[.*])scale=1920:1080,setsar=1:1(?P<s4>\[.*])$`)
+	require.Regexp(t, reg, cmd.FilterComplex())
+	params := getParams(reg, cmd.FilterComplex())
+	require.Equal(t, params["s1"], params["s1_2"])
+	require.Equal(t, params["s2"], params["s2_2"])
+
+	require.Equal(t, []string{
+		"-map", params["s4"], "-map", params["s3"], "-map", "0:a:0",
+		"-c:v", "libx264", "-force_key_frames", "expr:gte(t,n_forced*4)",
+		"-b:v:0", "5M", "-maxrate:v:0", "5350k", "-bufsize:v:0", "7500k", "-b:v:1", "2800k",
+		"-c:a", "aac", "-b:a", "128k",
+		"-f", "hls", "-hls_time", "4", "-hls_playlist_type", "vod", "-hls_flags", "independent_segments",
+		"-hls_segment_filename", "stream_%v_%03d.ts", "-master_pl_name", "master.m3u8",
+		"-var_stream_map", "v:0,agroup:audio v:1,agroup:audio a:0,agroup:audio",
+		"stream_%v.m3u8",
+	}, []string(cmd[4:]))
+
+	_, _, err = (&HLSLadder{Renditions: ladder.Renditions}).Build(video, nil)
+	require.Error(t, err)
+}
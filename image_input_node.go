@@ -0,0 +1,133 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ImageSequenceInputNode implements IInputNode
+var _ IInputNode = &ImageSequenceInputNode{}
+
+// ImageSequenceInputNode reads numbered images such as 'frame_%05d.png', or images matching a glob pattern, as a video.
+type ImageSequenceInputNode struct {
+	InputNode
+	FrameRate   int
+	StartNumber int
+	Glob        bool
+}
+
+func (i *ImageSequenceInputNode) ToString() []string {
+	res := make([]string, 0)
+	if i.FrameRate > 0 {
+		res = append(res, "-framerate", strconv.Itoa(i.FrameRate))
+	}
+	if i.StartNumber != 0 {
+		res = append(res, "-start_number", strconv.Itoa(i.StartNumber))
+	}
+	if i.Glob {
+		res = append(res, "-pattern_type", "glob")
+	}
+
+	return append(res, i.InputNode.ToString()...)
+}
+
+func (i *ImageSequenceInputNode) StreamInfo(stream string) StreamInfo {
+	res := i.InputNode.StreamInfo(stream)
+	if i.FrameRate > 0 {
+		res.FPS = float64Ptr(float64(i.FrameRate))
+	}
+
+	return res
+}
+
+// NewImageSequenceInputNode reads images matching pattern at the given frame rate, which can be 0 for the default frame
+// rate which is 25. pattern is either a printf like pattern such as 'frame_%05d.png' starting from startNumber or a
+// glob pattern such as '*.png' if glob is true.
+func NewImageSequenceInputNode(pattern string, frameRate, startNumber int, glob bool) *ImageSequenceInputNode {
+	return &ImageSequenceInputNode{
+		InputNode:   *NewInputNode(pattern, nil, nil),
+		FrameRate:   frameRate,
+		StartNumber: startNumber,
+		Glob:        glob,
+	}
+}
+
+// StillImageInputNode implements IInputNode
+var _ IInputNode = &StillImageInputNode{}
+
+// StillImageInputNode repeats a single image as a video for a duration.
+type StillImageInputNode struct {
+	InputNode
+	FrameRate int
+}
+
+func (i *StillImageInputNode) ToString() []string {
+	res := []string{"-loop", "1"}
+	if i.FrameRate > 0 {
+		res = append(res, "-framerate", strconv.Itoa(i.FrameRate))
+	}
+
+	return append(res, i.InputNode.ToString()...)
+}
+
+func (i *StillImageInputNode) StreamInfo(stream string) StreamInfo {
+	res := i.InputNode.StreamInfo(stream)
+	if i.FrameRate > 0 {
+		res.FPS = float64Ptr(float64(i.FrameRate))
+	}
+
+	return res
+}
+
+// NewStillImageInputNode repeats image for duration. frameRate can be 0 for the default frame rate which is 25.
+func NewStillImageInputNode(path string, duration time.Duration, frameRate int) *StillImageInputNode {
+	return &StillImageInputNode{
+		InputNode: *NewInputNode(path, &duration, nil),
+		FrameRate: frameRate,
+	}
+}
+
+type Slide struct {
+	Path     string
+	Duration time.Duration
+}
+
+// Slideshow builds a video from images, each one shown for its duration and scaled to w x h. Consecutive images are
+// joined with the xfade transition, such as 'fade' or 'slideleft', which lasts for transitionDuration. If transition is
+// empty they are simply concatenated. Each slide should last longer than the transition.
+func Slideshow(slides []Slide, w, h, fps int, transition string, transitionDuration time.Duration) (INode, error) {
+	if len(slides) == 0 {
+		return nil, fmt.Errorf("slideshow needs at least one slide")
+	}
+
+	nodes := make([]INode, 0, len(slides))
+	for _, s := range slides {
+		if transition != "" && s.Duration <= transitionDuration {
+			return nil, fmt.Errorf("slide %v should last longer than the transition", s.Path)
+		}
+
+		// xfade and concat need inputs of the same size and frame rate
+		var n INode = NewStillImageInputNode(s.Path, s.Duration, fps)
+		n = NewScaleFilterNode(n, w, h, true)
+		n = NewFpsFilterNode(n, fps)
+		nodes = append(nodes, n)
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+
+	if transition == "" {
+		return NewConcatFilter(nodes...), nil
+	}
+
+	res, length := nodes[0], slides[0].Duration
+	for i := 1; i < len(nodes); i++ {
+		offset := length - transitionDuration
+		res = NewXfadeFilter(res, nodes[i], transition, transitionDuration, offset)
+		length = offset + slides[i].Duration
+	}
+
+	return res, nil
+}
@@ -0,0 +1,50 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageInputs(t *testing.T) {
+	t.Run("sequence", func(t *testing.T) {
+		seq := NewImageSequenceInputNode("frame_%05d.png", 25, 100, false)
+		glob := NewImageSequenceInputNode("*.jpg", 30, 0, true)
+
+		cmd := Select([]INode{NewScaleFilterNode(seq, 100, 100, false), NewScaleFilterNode(glob, 100, 100, false)}, "out.mp4", nil)
+		require.Equal(t, []string{"-framerate", "25", "-start_number", "100", "-i", "frame_%05d.png"}, []string(cmd[:6]))
+		require.Equal(t, []string{"-framerate", "30", "-pattern_type", "glob", "-i", "*.jpg"}, []string(cmd[6:12]))
+		require.Equal(t, 30.0, *Info(glob).FPS)
+
+		seq = NewImageSequenceInputNode("frame_%05d.png", 0, 0, false)
+		require.Equal(t, []string{"-i", "frame_%05d.png"}, seq.ToString())
+		require.Nil(t, Info(seq).FPS)
+	})
+
+	t.Run("slideshow", func(t *testing.T) {
+		slides := []Slide{
+			{"a.jpg", 3 * time.Second},
+			{"b.png", 2500 * time.Millisecond},
+			{"c.jpg", 4 * time.Second},
+		}
+		show, err := Slideshow(slides, 1280, 720, 25, "fade", time.Second)
+		require.NoError(t, err)
+
+		cmd := Select([]INode{show}, "out.mp4", nil)
+		require.Equal(t, []string{"-loop", "1", "-framerate", "25", "-t", "00:00:03", "-i", "a.jpg"}, []string(cmd[:8]))
+		require.Equal(t, []string{"-loop", "1", "-framerate", "25", "-t", "00:00:02.500", "-i", "b.png"}, []string(cmd[8:16]))
+		require.Contains(t, cmd.FilterComplex(), "xfade=transition=fade:duration=1:offset=2[var_")
+		require.Contains(t, cmd.FilterComplex(), "xfade=transition=fade:duration=1:offset=3.5")
+		require.Equal(t, 7500*time.Millisecond, *Info(show).Duration)
+
+		show, err = Slideshow(slides, 1280, 720, 25, "", 0)
+		require.NoError(t, err)
+		cmd = Select([]INode{show}, "out.mp4", nil)
+		require.Contains(t, cmd.FilterComplex(), "concat=n=3:v=1:a=0")
+		require.Equal(t, 9500*time.Millisecond, *Info(show).Duration)
+
+		_, err = Slideshow(slides, 1280, 720, 25, "fade", 3*time.Second)
+		require.Error(t, err)
+	})
+}
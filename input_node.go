@@ -3,6 +3,7 @@ package ffmpegtree
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,16 +18,118 @@ type IInputNode interface {
 // InputNode implements IInputNode
 var _ IInputNode = &InputNode{}
 
+// InputOptions are demuxer and decoder options of an input. They are written before '-i' in a deterministic order,
+// Extra options being the last ones in the order they are given.
+type InputOptions struct {
+	// Format forces input format with '-f'.
+	Format string
+
+	// Re reads input at its native frame rate.
+	Re bool
+
+	ThreadQueueSize int
+
+	// AccurateSeek sets '-accurate_seek' or '-noaccurate_seek' if it is not nil.
+	AccurateSeek *bool
+
+	ItsOffset *time.Duration
+
+	// FrameRate sets '-r' and Framerate sets '-framerate' which is used by demuxers such as image2 or devices.
+	FrameRate, Framerate string
+
+	PixFmt string
+
+	// VideoDecoder and AudioDecoder select decoders with '-c:v' and '-c:a'.
+	VideoDecoder, AudioDecoder string
+
+	// Extra are arbitrary AVOptions of the demuxer or decoders such as {"-probesize", "32"}.
+	Extra []InputOption
+}
+
+type InputOption struct {
+	Key, Value string
+}
+
+func (o *InputOptions) ToString() []string {
+	res := make([]string, 0)
+	if o.Format != "" {
+		res = append(res, "-f", o.Format)
+	}
+	if o.Re {
+		res = append(res, "-re")
+	}
+	if o.ThreadQueueSize > 0 {
+		res = append(res, "-thread_queue_size", strconv.Itoa(o.ThreadQueueSize))
+	}
+	if o.AccurateSeek != nil {
+		if *o.AccurateSeek {
+			res = append(res, "-accurate_seek")
+		} else {
+			res = append(res, "-noaccurate_seek")
+		}
+	}
+	if o.ItsOffset != nil {
+		res = append(res, "-itsoffset", fmtSeconds(*o.ItsOffset))
+	}
+	if o.FrameRate != "" {
+		res = append(res, "-r", o.FrameRate)
+	}
+	if o.Framerate != "" {
+		res = append(res, "-framerate", o.Framerate)
+	}
+	if o.PixFmt != "" {
+		res = append(res, "-pix_fmt", o.PixFmt)
+	}
+	if o.VideoDecoder != "" {
+		res = append(res, "-c:v", o.VideoDecoder)
+	}
+	if o.AudioDecoder != "" {
+		res = append(res, "-c:a", o.AudioDecoder)
+	}
+	for _, opt := range o.Extra {
+		res = append(res, opt.Key)
+		if opt.Value != "" {
+			res = append(res, opt.Value)
+		}
+	}
+
+	return res
+}
+
+// Validate checks that options can be placed before '-i' without changing the meaning of the command.
+func (o *InputOptions) Validate() error {
+	if o.ThreadQueueSize < 0 {
+		return fmt.Errorf("thread queue size cannot be negative: %v", o.ThreadQueueSize)
+	}
+
+	for _, opt := range o.Extra {
+		if !strings.HasPrefix(opt.Key, "-") {
+			return fmt.Errorf("input option should start with '-': %v", opt.Key)
+		}
+		// an extra '-i' would make options after it belong to another input
+		if opt.Key == "-i" || opt.Value == "-i" {
+			return fmt.Errorf("input options cannot contain -i")
+		}
+	}
+
+	return nil
+}
+
 type InputNode struct {
 	BaseNode
 	InputName   string
 	Offset, Len *time.Duration
-	inputIdx    int
-	isLoop      bool
+	Options     InputOptions
+
+	// Metadata is the probed metadata of the input, if available. It is used to analyze streams in the graph.
+	Metadata *ProbeResult
+	inputIdx int
+	isLoop   bool
 }
 
 func (i *InputNode) ToString() []string {
 	res := make([]string, 0)
+	res = append(res, i.Options.ToString()...)
 	res = append(res, "-i", i.InputName)
 	if i.Offset != nil {
 		res = append([]string{"-ss", fmtDuration(*i.Offset)}, res...)
@@ -53,6 +156,10 @@ func (i *InputNode) GetInputName() string {
 	return i.InputName
 }
 
+func (i *InputNode) GetMetadata() *ProbeResult {
+	return i.Metadata
+}
+
 func NewInputNode(name string, len, offset *time.Duration) *InputNode {
 	return &InputNode{
 		BaseNode:  NewBaseNode(nil),
@@ -62,6 +169,19 @@ func NewInputNode(name string, len, offset *time.Duration) *InputNode {
 	}
 }
 
+// NewInputNodeWithOptions opens an input file with demuxer and decoder options.
+func NewInputNodeWithOptions(name string, opts InputOptions) (*InputNode, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &InputNode{
+		BaseNode:  NewBaseNode(nil),
+		InputName: name,
+		Options:   opts,
+	}, nil
+}
+
 // NewAudioInputNode opens an input file and selects audio stream.
 func NewAudioInputNode(name string, len, offset *time.Duration) INode {
 	return NewSelectStreamNode(NewInputNode(name, len, offset), AudioStream)
@@ -0,0 +1,135 @@
+package ffmpegtree
+
+import (
+	"sort"
+	"time"
+)
+
+// Interpolation decides how a value changes between two keyframes.
+type Interpolation int
+
+const (
+	Linear Interpolation = iota
+	EaseIn
+	EaseOut
+	EaseInOut
+
+	// Hold keeps the value until the next keyframe and jumps to it.
+	Hold
+
+	// Bezier eases with a cubic bezier curve whose control points are given by Keyframe's Y1 and Y2.
+	Bezier
+)
+
+type Keyframe struct {
+	At    time.Duration
+	Value float64
+
+	// Interpolation is used between this keyframe and the next one.
+	Interpolation Interpolation
+
+	// Y1 and Y2 are the progress values of bezier control points placed at 1/3 and 2/3 of the segment. 0 and 1
+	// respectively make it linear, values out of [0, 1] overshoot.
+	Y1, Y2 float64
+}
+
+// Keyframes animates a value over time. It compiles into a piecewise ffmpeg expression, so it can be used wherever the
+// library takes an Expression, such as overlay position, crop offsets or rotate angle.
+type Keyframes struct {
+	frames []Keyframe
+}
+
+func NewKeyframes(frames ...Keyframe) *Keyframes {
+	k := &Keyframes{}
+	for _, f := range frames {
+		k.AddKeyframe(f)
+	}
+
+	return k
+}
+
+func (k *Keyframes) AddKeyframe(f Keyframe) *Keyframes {
+	k.frames = append(k.frames, f)
+	sort.SliceStable(k.frames, func(i, j int) bool {
+		return k.frames[i].At < k.frames[j].At
+	})
+
+	return k
+}
+
+func (k *Keyframes) Add(at time.Duration, value float64, interpolation Interpolation) *Keyframes {
+	return k.AddKeyframe(Keyframe{At: at, Value: value, Interpolation: interpolation})
+}
+
+func (k *Keyframes) AddBezier(at time.Duration, value float64, y1, y2 float64) *Keyframes {
+	return k.AddKeyframe(Keyframe{At: at, Value: value, Interpolation: Bezier, Y1: y1, Y2: y2})
+}
+
+// Expr returns the animated value as an expression of time. Value of the first keyframe is used before it and value of
+// the last one is used after it.
+func (k *Keyframes) Expr() Expr {
+	return k.ExprOf(T())
+}
+
+// ExprOf returns the animated value as an expression of the given variable. For instance with N() keyframes are placed
+// at frame numbers, At of 25*time.Second being the 25th frame.
+func (k *Keyframes) ExprOf(x Expr) Expr {
+	if len(k.frames) == 0 {
+		return Num(0)
+	}
+
+	last := k.frames[len(k.frames)-1]
+	var res Expr = Num(last.Value)
+	for i := len(k.frames) - 2; i >= 0; i-- {
+		res = If(Lt(x, Num(k.frames[i+1].At.Seconds())), k.segment(x, i), res)
+	}
+
+	return If(Lt(x, Num(k.frames[0].At.Seconds())), Num(k.frames[0].Value), res)
+}
+
+// Expression returns Expr as an Expression.
+func (k *Keyframes) Expression() Expression {
+	return ToExpression(k.Expr())
+}
+
+// ValueAt evaluates the animated value at the given time.
+func (k *Keyframes) ValueAt(t time.Duration) float64 {
+	return k.Expr().Eval(At(t.Seconds()))
+}
+
+// segment returns the expression interpolating between keyframes i and i+1.
+func (k *Keyframes) segment(x Expr, i int) Expr {
+	from, to := k.frames[i], k.frames[i+1]
+	if from.Interpolation == Hold || from.Value == to.Value || from.At == to.At {
+		return Num(from.Value)
+	}
+
+	start, length := from.At.Seconds(), (to.At - from.At).Seconds()
+	u := Div(x, Num(length))
+	if start != 0 {
+		u = Div(Sub(x, Num(start)), Num(length))
+	}
+
+	var p Expr
+	switch from.Interpolation {
+	case EaseIn:
+		p = Mul(u, u)
+	case EaseOut:
+		p = Mul(u, Sub(Num(2), u))
+	case EaseInOut:
+		p = Mul(Mul(u, u), Sub(Num(3), Mul(Num(2), u)))
+	case Bezier:
+		v := Sub(Num(1), u)
+		p = Add(Add(
+			Mul(Mul(Mul(Num(3*from.Y1), v), v), u),
+			Mul(Mul(Mul(Num(3*from.Y2), v), u), u)),
+			Mul(Mul(u, u), u))
+	default:
+		p = u
+	}
+
+	if to.Value < from.Value {
+		return Sub(Num(from.Value), Mul(Num(from.Value-to.Value), p))
+	}
+	return Add(Num(from.Value), Mul(Num(to.Value-from.Value), p))
+}
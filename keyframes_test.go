@@ -0,0 +1,44 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyframes(t *testing.T) {
+	t.Run("linear", func(t *testing.T) {
+		k := NewKeyframes().Add(time.Second, 0, Linear).Add(3*time.Second, 100, Linear)
+
+		require.Equal(t, "if(lt(t,1),0,if(lt(t,3),0+100*(t-1)/2,100))", k.Expr().String())
+		require.Equal(t, 0.0, k.ValueAt(0))
+		require.Equal(t, 50.0, k.ValueAt(2*time.Second))
+		require.Equal(t, 100.0, k.ValueAt(5*time.Second))
+	})
+
+	t.Run("interpolations", func(t *testing.T) {
+		at := func(i Interpolation) float64 {
+			return NewKeyframes().Add(0, 0, i).Add(time.Second, 1, Linear).ValueAt(250 * time.Millisecond)
+		}
+
+		require.Equal(t, 0.25, at(Linear))
+		require.Equal(t, 0.0625, at(EaseIn))
+		require.Equal(t, 0.4375, at(EaseOut))
+		require.Equal(t, 0.15625, at(EaseInOut))
+		require.Equal(t, 0.0, at(Hold))
+
+		linearBezier := NewKeyframes().AddBezier(0, 0, 1.0/3, 2.0/3).Add(time.Second, 1, Linear)
+		require.InDelta(t, 0.25, linearBezier.ValueAt(250*time.Millisecond), 1e-9)
+	})
+
+	t.Run("in filters", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		x := NewKeyframes().Add(0, 0, Hold).Add(time.Second, 10, Linear)
+		ov := NewOverlayFilterNode(i, i, x.Expression(), "0")
+		require.Equal(t, "overlay=x='if(lt(t,0),0,if(lt(t,1),0,10))':y='0'", ov.FilterString())
+
+		vol := NewVolumeExprFilter(i, NewKeyframes().Add(0, 1, Linear).Add(2*time.Second, 0, Linear).Expr())
+		require.Equal(t, "volume=volume='if(lt(t,0),1,if(lt(t,2),1-1*t/2,0))':eval=frame", vol.FilterString())
+	})
+}
@@ -0,0 +1,209 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HStackFilter places inputs side by side. Inputs should have the same height.
+type HStackFilter struct {
+	BaseFilterNode
+
+	// Shortest ends the output with the shortest input.
+	Shortest bool
+}
+
+func (f *HStackFilter) FilterString() string {
+	return stackString("hstack", len(f.GetInputs()), f.Shortest)
+}
+
+func NewHStackFilter(inputs ...INode) *HStackFilter {
+	return &HStackFilter{BaseFilterNode: *NewBaseFilterNode(inputs, randStr())}
+}
+
+// VStackFilter places inputs on top of each other. Inputs should have the same width.
+type VStackFilter struct {
+	BaseFilterNode
+	Shortest bool
+}
+
+func (f *VStackFilter) FilterString() string {
+	return stackString("vstack", len(f.GetInputs()), f.Shortest)
+}
+
+func NewVStackFilter(inputs ...INode) *VStackFilter {
+	return &VStackFilter{BaseFilterNode: *NewBaseFilterNode(inputs, randStr())}
+}
+
+// XStackFilter places every input at the position given in Layout, which is in the form of 'x_y' where x and y are
+// sums of pixels or of widths and heights of inputs such as 'w0+w1_h0'.
+type XStackFilter struct {
+	BaseFilterNode
+	Layout   []string
+	Shortest bool
+
+	// Fill is the color of the area which is not covered by any input. It is left to ffmpeg if empty.
+	Fill string
+}
+
+func (f *XStackFilter) FilterString() string {
+	res := fmt.Sprintf("%v:layout=%v", stackString("xstack", len(f.GetInputs()), f.Shortest), strings.Join(f.Layout, "|"))
+	if f.Fill != "" {
+		res += ":fill=" + f.Fill
+	}
+
+	return res
+}
+
+// NewXStackFilter places inputs in a grid of the given columns, row by row. Layout assumes that inputs have the same
+// size, it can be changed for other arrangements.
+func NewXStackFilter(columns int, inputs ...INode) *XStackFilter {
+	return &XStackFilter{
+		BaseFilterNode: *NewBaseFilterNode(inputs, randStr()),
+		Layout:         gridLayout(len(inputs), columns),
+	}
+}
+
+func stackString(name string, inputs int, shortest bool) string {
+	res := fmt.Sprintf("%v=inputs=%v", name, inputs)
+	if shortest {
+		res += ":shortest=1"
+	}
+
+	return res
+}
+
+// gridLayout returns positions of count inputs placed row by row in a grid, in terms of the size of the first input.
+func gridLayout(count, columns int) []string {
+	res := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		res = append(res, repeatedSum("w0", i%columns)+"_"+repeatedSum("h0", i/columns))
+	}
+
+	return res
+}
+
+// repeatedSum returns v added n times, such as 'w0+w0', or 0.
+func repeatedSum(v string, n int) string {
+	if n == 0 {
+		return "0"
+	}
+
+	return strings.TrimSuffix(strings.Repeat(v+"+", n), "+")
+}
+
+func (f *HStackFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	res.Width = sumSizes(inputs, func(i StreamInfo) *int { return i.Width })
+	return res
+}
+
+func (f *VStackFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	res.Height = sumSizes(inputs, func(i StreamInfo) *int { return i.Height })
+	return res
+}
+
+func (f *XStackFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	// size depends on the layout
+	res := inputs[0]
+	res.Width, res.Height = nil, nil
+	return res
+}
+
+func sumSizes(inputs []StreamInfo, size func(StreamInfo) *int) *int {
+	total := 0
+	for _, in := range inputs {
+		s := size(in)
+		if s == nil {
+			return nil
+		}
+		total += *s
+	}
+
+	return &total
+}
+
+// Grid places inputs row by row in a grid of cells of cellWidth x cellHeight. Every input is scaled to fit in its cell
+// keeping its aspect ratio and padded to the cell size, so inputs of any size can be used. Cells without an input are
+// filled with black.
+func Grid(rows, columns int, inputs []INode, cellWidth, cellHeight int) (INode, error) {
+	if rows <= 0 || columns <= 0 || cellWidth <= 0 || cellHeight <= 0 {
+		return nil, fmt.Errorf("grid and cell size should be positive")
+	}
+	if len(inputs) == 0 || len(inputs) > rows*columns {
+		return nil, fmt.Errorf("grid of %vx%v cannot have %v inputs", rows, columns, len(inputs))
+	}
+
+	cells := make([]INode, 0, len(inputs))
+	for _, in := range inputs {
+		cell, err := Fit(in, cellWidth, cellHeight, Contain)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+
+	var res INode = cells[0]
+	if len(cells) > 1 {
+		stack := NewXStackFilter(columns, cells...)
+		layout := make([]string, 0, len(cells))
+		for i := range cells {
+			layout = append(layout, fmt.Sprintf("%v_%v", i%columns*cellWidth, i/columns*cellHeight))
+		}
+		stack.Layout = layout
+		if len(cells) < rows*columns {
+			stack.Fill = "black"
+		}
+		res = stack
+	}
+
+	// xstack outputs only the bounding box of its inputs, hence empty rows and columns are added by padding
+	usedColumns, usedRows := columns, (len(cells)+columns-1)/columns
+	if len(cells) < columns {
+		usedColumns = len(cells)
+	}
+	if usedColumns < columns || usedRows < rows {
+		pad := NewPadFilter(res, columns*cellWidth, rows*cellHeight)
+		pad.X, pad.Y = "0", "0"
+		res = pad
+	}
+
+	return res, nil
+}
+
+type Corner int
+
+const (
+	TopLeft Corner = iota
+	TopRight
+	BottomLeft
+	BottomRight
+)
+
+// PictureInPicture places inset at the corner of main, margin pixels away from the edges. inset is scaled to the given
+// ratio of main's width keeping its aspect ratio, hence width of main should be known, see Info.
+func PictureInPicture(main, inset INode, corner Corner, margin int, scale float64) (*OverlayFilterNode, error) {
+	if scale <= 0 || scale > 1 {
+		return nil, fmt.Errorf("scale should be in (0, 1]")
+	}
+
+	w := Info(main).Width
+	if w == nil {
+		return nil, fmt.Errorf("width of main video is not known")
+	}
+
+	// width is rounded to an even number, which most encoders need
+	insetW := int(float64(*w)*scale/2+0.5) * 2
+	scaled := NewScaleFilterNode(inset, insetW, -2, true)
+
+	x, y := Expression(fmt.Sprintf("%v", margin)), Expression(fmt.Sprintf("%v", margin))
+	if corner == TopRight || corner == BottomRight {
+		x = Expression(fmt.Sprintf("main_w-overlay_w-%v", margin))
+	}
+	if corner == BottomLeft || corner == BottomRight {
+		y = Expression(fmt.Sprintf("main_h-overlay_h-%v", margin))
+	}
+
+	return NewOverlayFilterNode(main, scaled, x, y), nil
+}
@@ -0,0 +1,69 @@
+package ffmpegtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStacks(t *testing.T) {
+	i1, i2, i3 := NewInputNode("1.mp4", nil, nil), NewInputNode("2.mp4", nil, nil), NewInputNode("3.mp4", nil, nil)
+
+	h := NewHStackFilter(i1, i2)
+	h.Shortest = true
+	require.Equal(t, "hstack=inputs=2:shortest=1", h.FilterString())
+	require.Equal(t, "vstack=inputs=3", NewVStackFilter(i1, i2, i3).FilterString())
+	require.Equal(t, "xstack=inputs=3:layout=0_0|w0_0|0_h0", NewXStackFilter(2, i1, i2, i3).FilterString())
+	require.Equal(t, "xstack=inputs=3:layout=0_0|w0_0|w0+w0_0", NewXStackFilter(3, i1, i2, i3).FilterString())
+
+	p := probedInput(t)
+	info := Info(NewHStackFilter(p, NewScaleFilterNode(p, 960, 1080, false)))
+	require.Equal(t, 2880, *info.Width)
+	require.Equal(t, 1080, *info.Height)
+}
+
+func TestGrid(t *testing.T) {
+	i1, i2, i3 := NewInputNode("1.mp4", nil, nil), NewInputNode("2.mp4", nil, nil), NewInputNode("3.mp4", nil, nil)
+
+	g, err := Grid(2, 2, []INode{i1, i2, i3}, 640, 360)
+	require.NoError(t, err)
+	cmd := Select([]INode{g}, "out.mp4", nil)
+
+	cell := "scale=iw*sar:ih,setsar=1,scale=640:360:force_original_aspect_ratio=decrease,setsar=1:1,pad=640:360:'(ow-iw)/2':'(oh-ih)/2'"
+	require.Contains(t, cmd.FilterComplex(), "[0:0]"+cell)
+	require.Contains(t, cmd.FilterComplex(), "[1:0]"+cell)
+	require.Contains(t, cmd.FilterComplex(), "[2:0]"+cell)
+	require.Contains(t, cmd.FilterComplex(), "xstack=inputs=3:layout=0_0|640_0|0_360:fill=black")
+
+	// empty rows and columns are not left out
+	g, err = Grid(2, 2, []INode{i1, i2}, 640, 360)
+	require.NoError(t, err)
+	cmd = Select([]INode{g}, "out.mp4", nil)
+	require.Contains(t, cmd.FilterComplex(), "xstack=inputs=2:layout=0_0|640_0:fill=black,pad=1280:720:'0':'0'")
+	require.Equal(t, 1280, *Info(g).Width)
+	require.Equal(t, 720, *Info(g).Height)
+
+	g, err = Grid(2, 2, []INode{probedInput(t)}, 640, 360)
+	require.NoError(t, err)
+	cmd = Select([]INode{g}, "out.mp4", nil)
+	require.Equal(t, "[0:0]"+cell+",pad=1280:720:'0':'0'", cmd.FilterComplex())
+	require.Equal(t, 1280, *Info(g).Width)
+	require.Equal(t, 720, *Info(g).Height)
+
+	_, err = Grid(1, 2, []INode{i1, i2, i3}, 640, 360)
+	require.Error(t, err)
+}
+
+func TestPictureInPicture(t *testing.T) {
+	main := probedInput(t)
+	inset := NewInputNode("cam.mp4", nil, nil)
+
+	pip, err := PictureInPicture(main, inset, BottomRight, 20, 0.25)
+	require.NoError(t, err)
+	cmd := Select([]INode{pip}, "out.mp4", nil)
+	require.Contains(t, cmd.FilterComplex(), "[1:0]scale=480:-2,setsar=1:1")
+	require.Contains(t, cmd.FilterComplex(), "overlay=x='main_w-overlay_w-20':y='main_h-overlay_h-20'")
+
+	_, err = PictureInPicture(inset, main, TopLeft, 20, 0.25)
+	require.Error(t, err)
+}
@@ -49,6 +49,18 @@ func NewMap(fromNode INode, opts ...string) IMap {
 		}
 	}
 
+	// '[0:a]' is the first audio stream while '-map 0:a' maps all of them
+	if ssn, ok := fromNode.(*SelectStreamNode); ok {
+		stream := ssn.idx
+		if stream == "a" || stream == "v" {
+			stream += ":0"
+		}
+		return &MapFromInputNode{
+			input:  ssn.input,
+			stream: stream,
+		}
+	}
+
 	fn, ok := fromNode.(IFilterNode)
 	if ok {
 		return &MapFromFilterNode{
@@ -0,0 +1,138 @@
+package ffmpegtree
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Chapter is a chapter marker of the output. If End is zero, chapter ends where the next one starts.
+type Chapter struct {
+	Title      string
+	Start, End time.Duration
+}
+
+// metadataSource is an input whose metadata or chapters are copied to the output with flag, such as '-map_metadata'.
+type metadataSource struct {
+	flag  string
+	input IInputNode
+}
+
+// SetMetadata sets a global metadata of the output such as title.
+func (e *FFmpegExecutor) SetMetadata(key, value string) {
+	e.outputArgs = append(e.outputArgs, "-metadata", fmt.Sprintf("%v=%v", key, value))
+}
+
+// SetStreamMetadata sets metadata of output streams such as language. stream selects streams of the output, not of an
+// input.
+func (e *FFmpegExecutor) SetStreamMetadata(stream StreamSpecifier, key, value string) {
+	e.outputArgs = append(e.outputArgs, "-metadata:s:"+stream.spec(), fmt.Sprintf("%v=%v", key, value))
+}
+
+// SetDisposition sets dispositions such as 'default' or 'forced' of output streams. Calling it without any disposition
+// clears them.
+func (e *FFmpegExecutor) SetDisposition(stream StreamSpecifier, dispositions ...string) {
+	value := strings.Join(dispositions, "+")
+	if value == "" {
+		value = "0"
+	}
+	e.outputArgs = append(e.outputArgs, "-disposition:"+stream.spec(), value)
+}
+
+// MapMetadataFrom copies global metadata of input to the output. input is added to the command if it is not used.
+func (e *FFmpegExecutor) MapMetadataFrom(input IInputNode) {
+	e.metadataSources = append(e.metadataSources, metadataSource{flag: "-map_metadata", input: input})
+}
+
+// MapChaptersFrom copies chapters of input to the output. input is added to the command if it is not used.
+func (e *FFmpegExecutor) MapChaptersFrom(input IInputNode) {
+	e.metadataSources = append(e.metadataSources, metadataSource{flag: "-map_chapters", input: input})
+}
+
+// SetChapters writes chapters to an FFMETADATA file at path and adds it as an input to copy chapters from. If path is
+// empty, chapters are written to a temporary file, which should be removed by the caller after ffmpeg is run. It
+// returns the path of the written file.
+func (e *FFmpegExecutor) SetChapters(path string, chapters []Chapter) (string, error) {
+	content, err := FfmetadataChapters(chapters)
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		f, err := os.CreateTemp("", "ffmetadata-*.txt")
+		if err != nil {
+			return "", fmt.Errorf("create chapters file: %w", err)
+		}
+		path = f.Name()
+		_, err = f.WriteString(content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+			return "", fmt.Errorf("write chapters file: %w", err)
+		}
+	} else if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("write chapters file: %w", err)
+	}
+
+	in, err := NewInputNodeWithOptions(path, InputOptions{Format: "ffmetadata"})
+	if err != nil {
+		return "", err
+	}
+	e.MapChaptersFrom(in)
+
+	return path, nil
+}
+
+// FfmetadataChapters returns chapters in FFMETADATA format.
+func FfmetadataChapters(chapters []Chapter) (string, error) {
+	b := &bytes.Buffer{}
+	b.WriteString(";FFMETADATA1\n")
+	for i, c := range chapters {
+		end := c.End
+		if end == 0 && i+1 < len(chapters) {
+			end = chapters[i+1].Start
+		}
+		if end <= c.Start {
+			return "", fmt.Errorf("chapter %v should end after it starts", c.Title)
+		}
+
+		b.WriteString("[CHAPTER]\nTIMEBASE=1/1000\n")
+		fmt.Fprintf(b, "START=%v\nEND=%v\n", c.Start.Milliseconds(), end.Milliseconds())
+		if c.Title != "" {
+			fmt.Fprintf(b, "title=%v\n", escapeFfmetadata(c.Title))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// escapeFfmetadata escapes special characters of FFMETADATA format with a backslash.
+func escapeFfmetadata(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n").Replace(v)
+}
+
+// setMetadataInputIdx adds inputs which metadata or chapters are copied from to the command if they are not used
+// anywhere else.
+func (e *FFmpegExecutor) setMetadataInputIdx() {
+	for _, s := range e.metadataSources {
+		if !e.isInInputs(s.input) {
+			s.input.SetInputIdx(len(e.inputs))
+			e.inputs = append(e.inputs, s.input)
+		}
+	}
+}
+
+// outputOptions returns output options which are set through the executor, such as metadata, dispositions, chapters
+// and codecs of subtitle tracks.
+func (e *FFmpegExecutor) outputOptions() []string {
+	res := make([]string, 0)
+	for _, s := range e.metadataSources {
+		res = append(res, s.flag, fmt.Sprintf("%v", s.input.GetInputIdx()))
+	}
+
+	return append(res, e.outputArgs...)
+}
@@ -0,0 +1,64 @@
+package ffmpegtree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputMetadata(t *testing.T) {
+	video, audio := NewInputNode("video.mp4", nil, nil), NewInputNode("dub.m4a", nil, nil)
+	chaptersPath := filepath.Join(t.TempDir(), "chapters.txt")
+
+	exec := NewFfmpegExecutor([]IMap{NewMap(video, "v"), NewMap(audio, "a")}, "out.mp4", []string{"-c", "copy"})
+	exec.SetMetadata("title", "My Movie")
+	exec.SetStreamMetadata(AudioStreams().Nth(0), "language", "tur")
+	exec.SetDisposition(AudioStreams().Nth(0), "default", "forced")
+	exec.MapMetadataFrom(video)
+	path, err := exec.SetChapters(chaptersPath, []Chapter{
+		{Title: "Intro", Start: 0},
+		{Title: "Part 1; a=b", Start: time.Minute, End: 90 * time.Second},
+	})
+	require.NoError(t, err)
+	require.Equal(t, chaptersPath, path)
+
+	cmd := exec.ToFfmpeg()
+	require.Equal(t, FfmpegCommand{
+		"-i", "video.mp4", "-i", "dub.m4a", "-f", "ffmetadata", "-i", chaptersPath,
+		"-map", "0:v", "-map", "1:a",
+		"-map_metadata", "0", "-map_chapters", "2",
+		"-metadata", "title=My Movie", "-metadata:s:a:0", "language=tur", "-disposition:a:0", "default+forced",
+		"-c", "copy", "out.mp4",
+	}, cmd)
+
+	content, err := os.ReadFile(chaptersPath)
+	require.NoError(t, err)
+	require.Equal(t, `;FFMETADATA1
+[CHAPTER]
+TIMEBASE=1/1000
+START=0
+END=60000
+title=Intro
+[CHAPTER]
+TIMEBASE=1/1000
+START=60000
+END=90000
+title=Part 1\; a\=b
+`, string(content))
+
+	_, err = exec.SetChapters(chaptersPath, []Chapter{{Title: "Last", Start: time.Second}})
+	require.Error(t, err)
+
+	// the temporary file is returned to be removed
+	exec = NewFfmpegExecutor([]IMap{NewMap(video, "v")}, "out.mp4", nil)
+	path, err = exec.SetChapters("", []Chapter{{Title: "Intro", Start: 0, End: time.Minute}})
+	require.NoError(t, err)
+	defer os.Remove(path)
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "title=Intro")
+	require.Equal(t, []string{"-f", "ffmetadata", "-i", path}, []string(exec.ToFfmpeg()[2:6]))
+}
@@ -0,0 +1,268 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Optimizer rewrites a graph before it is compiled into a filter graph to get rid of redundant work. Each pass can be
+// toggled individually.
+type Optimizer struct {
+	// DeadNodeElimination drops output nodes whose streams are never mapped. It only has an effect when at least one
+	// map refers to a filter node, since otherwise unlabeled outputs are what ends up in the output file.
+	DeadNodeElimination bool
+
+	// CommonSubexpressionElimination merges structurally equal nodes, such as identical subtrees built twice from the
+	// same input. Merged nodes end up with more than one dependent and are shared by the split nodes inserted later.
+	CommonSubexpressionElimination bool
+
+	// Folding combines adjacent compatible filters such as consecutive ScaleFilterNode's, VideoSpeedFilter's or
+	// VolumeFilter's into one.
+	Folding bool
+}
+
+// NewOptimizer returns an optimizer with all passes enabled.
+func NewOptimizer() *Optimizer {
+	return &Optimizer{
+		DeadNodeElimination:            true,
+		CommonSubexpressionElimination: true,
+		Folding:                        true,
+	}
+}
+
+// Optimize runs enabled passes on the graph and returns new output nodes. Nodes are rewritten in place and maps are
+// redirected to the nodes that replaced their streams.
+func (o *Optimizer) Optimize(nodes []INode, maps []IMap) []INode {
+	if o.DeadNodeElimination {
+		nodes = eliminateDeadNodes(nodes, maps)
+	}
+
+	if o.Folding {
+		foldFilters(nodes, maps)
+	}
+
+	if o.CommonSubexpressionElimination {
+		nodes = eliminateCommonSubexpressions(nodes, maps)
+	}
+
+	return nodes
+}
+
+// eliminateDeadNodes returns output nodes which are either mapped or used by a mapped node.
+func eliminateDeadNodes(nodes []INode, maps []IMap) []INode {
+	mapped := make([]INode, 0)
+	for _, m := range maps {
+		if _, ok := m.GetStreamNode().(IInputNode); !ok {
+			mapped = append(mapped, m.GetStreamNode())
+		}
+	}
+	if len(mapped) == 0 {
+		return nodes
+	}
+
+	live := make(map[string]bool)
+	for _, n := range mapped {
+		markLive(n, live)
+	}
+
+	res := make([]INode, 0, len(nodes))
+	for _, n := range nodes {
+		if live[n.GetID()] {
+			res = append(res, n)
+		}
+	}
+
+	return res
+}
+
+func markLive(t INode, live map[string]bool) {
+	if live[t.GetID()] {
+		return
+	}
+	live[t.GetID()] = true
+
+	for _, node := range t.GetInputs() {
+		markLive(node, live)
+	}
+}
+
+// foldFilters traverses the graph and merges every filter into its input if they are compatible and the input is not
+// used anywhere else.
+func foldFilters(nodes []INode, maps []IMap) {
+	d := GetDependents(nodes...)
+
+	// output and mapped nodes should stay in the graph even if they could be folded into their dependents
+	pinned := make(map[string]bool)
+	for _, n := range nodes {
+		pinned[n.GetID()] = true
+	}
+	for _, m := range maps {
+		pinned[m.GetStreamNode().GetID()] = true
+	}
+
+	visited := make(map[string]bool)
+	for _, n := range nodes {
+		foldFilter(n, d, pinned, visited)
+	}
+}
+
+func foldFilter(t INode, d *DependentsMap, pinned, visited map[string]bool) {
+	if visited[t.GetID()] {
+		return
+	}
+	visited[t.GetID()] = true
+
+	// inputs are folded first so that a chain of compatible filters collapses into its last node
+	for _, node := range t.GetInputs() {
+		foldFilter(node, d, pinned, visited)
+	}
+
+	if len(t.GetInputs()) != 1 {
+		return
+	}
+
+	inner := t.GetInputs()[0]
+	if pinned[inner.GetID()] || len(d.Get(inner)) != 1 {
+		return
+	}
+
+	if foldPair(t, inner) {
+		t.SetInputs(inner.GetInputs())
+	}
+}
+
+// foldPair merges inner filter's effect into outer filter and reports whether it did so.
+func foldPair(outer, inner INode) bool {
+	outerFn, ok := outer.(IFilterNode)
+	if !ok || outerFn.EnableExpr() != "" {
+		return false
+	}
+	innerFn, ok := inner.(IFilterNode)
+	if !ok || innerFn.EnableExpr() != "" {
+		return false
+	}
+
+	switch o := outer.(type) {
+	case *ScaleFilterNode:
+		// outer scale decides the final size only if it does not depend on the input's aspect ratio. Setting sar in the
+		// inner scale changes the aspect ratio outer scale sees hence it is only safe when outer one sets it too.
+		i, ok := inner.(*ScaleFilterNode)
+		if !ok || o.W <= 0 || o.H <= 0 || o.ForceOriginalAspectRatio != "" || (i.SetSar && !o.SetSar) {
+			return false
+		}
+		return true
+
+	case *VideoSpeedFilter:
+		i, ok := inner.(*VideoSpeedFilter)
+		if !ok {
+			return false
+		}
+		o.PresentationTimeStamps *= i.PresentationTimeStamps
+		return true
+
+	case *VolumeFilter:
+		i, ok := inner.(*VolumeFilter)
+		if !ok || o.volExpr != nil || i.volExpr != nil {
+			return false
+		}
+
+		// volume is rendered with two decimals, the product should not be rounded to something else such as 0.00
+		vol := float64(o.vol * i.vol)
+		if math.Abs(vol*100-math.Round(vol*100)) > 1e-4 {
+			return false
+		}
+		o.vol *= i.vol
+		return true
+	}
+
+	return false
+}
+
+// eliminateCommonSubexpressions merges structurally equal nodes and returns deduplicated output nodes.
+func eliminateCommonSubexpressions(nodes []INode, maps []IMap) []INode {
+	canon := make(map[string]INode)
+	byKey := make(map[string]INode)
+
+	res := make([]INode, 0, len(nodes))
+	seen := make(map[string]bool)
+	for _, n := range nodes {
+		c := canonicalize(n, canon, byKey)
+		if !seen[c.GetID()] {
+			seen[c.GetID()] = true
+			res = append(res, c)
+		}
+	}
+
+	for _, m := range maps {
+		switch m := m.(type) {
+		case *MapFromFilterNode:
+			if c, ok := canon[m.filterNode.GetID()]; ok {
+				m.filterNode = c.(IFilterNode)
+			}
+		case *MapFromInputNode:
+			if c, ok := canon[m.input.GetID()]; ok {
+				m.input = c.(IInputNode)
+			}
+		}
+	}
+
+	return res
+}
+
+// canonicalize replaces inputs of the node with their canonical versions and returns the canonical version of the node
+// itself, which is the first structurally equal node encountered.
+func canonicalize(t INode, canon, byKey map[string]INode) INode {
+	if c, ok := canon[t.GetID()]; ok {
+		return c
+	}
+
+	inps := t.GetInputs()
+	for i, inp := range inps {
+		inps[i] = canonicalize(inp, canon, byKey)
+	}
+	t.SetInputs(inps)
+	if ssn, ok := t.(*SelectStreamNode); ok {
+		ssn.input = inps[0].(IInputNode)
+	}
+
+	res := t
+	if key, ok := structuralKey(t); ok {
+		if c, ok := byKey[key]; ok {
+			res = c
+		} else {
+			byKey[key] = t
+		}
+	}
+
+	canon[t.GetID()] = res
+	return res
+}
+
+// structuralKey returns a key which is equal for two nodes if and only if they produce the same stream. Nodes which
+// should never be merged return false.
+func structuralKey(t INode) (string, bool) {
+	key := ""
+	switch n := t.(type) {
+	case ISplitNode, IMergeNode:
+		// their out stream names are stateful, so they are not merged
+		return "", false
+	case *ReaderInputNode:
+		// every reader is a different stream even if the pipes are not wired yet
+		return "", false
+	case IFilterNode:
+		key = fmt.Sprintf("%T|%v", n, FilterNodeToStr(n))
+	case *SelectStreamNode:
+		key = fmt.Sprintf("%T|%v", n, n.idx)
+	case IInputNode:
+		key = fmt.Sprintf("%T|%v", n, strings.Join(n.ToString(), " "))
+	default:
+		return "", false
+	}
+
+	for _, inp := range t.GetInputs() {
+		key += "|" + inp.GetID()
+	}
+
+	return key, true
+}
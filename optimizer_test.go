@@ -0,0 +1,133 @@
+package ffmpegtree
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func optimizedSelect(o *Optimizer, nodes []INode, maps ...IMap) FfmpegCommand {
+	exec := NewFfmpegExecutor(maps, "out.mp4", nil)
+	exec.SetOptimizer(o)
+	return exec.ToFfmpeg(nodes...)
+}
+
+func TestOptimizerDeadNodeElimination(t *testing.T) {
+	t.Run("drops unmapped outputs", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		used := NewScaleFilterNode(i, 100, 100, false)
+		unused := NewScaleFilterNode(i, 200, 200, false)
+
+		cmd := optimizedSelect(&Optimizer{DeadNodeElimination: true}, []INode{used, unused}, NewMap(used))
+		require.Regexp(t, regexp.MustCompile(`^\[0:0]scale=100:100\[var_\d+]$`), cmd.FilterComplex())
+	})
+
+	t.Run("keeps everything when only inputs are mapped", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		s := NewScaleFilterNode(i, 100, 100, false)
+
+		cmd := optimizedSelect(&Optimizer{DeadNodeElimination: true}, []INode{s}, NewMap(i, "a"))
+		require.Equal(t, `[0:0]scale=100:100`, cmd.FilterComplex())
+	})
+}
+
+func TestOptimizerFolding(t *testing.T) {
+	t.Run("folds scales", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		var n INode = NewScaleFilterNode(i, 1200, -2, false)
+		n = NewScaleFilterNode(n, 400, 400, true)
+
+		cmd := optimizedSelect(&Optimizer{Folding: true}, []INode{n})
+		require.Equal(t, `[0:0]scale=400:400,setsar=1:1`, cmd.FilterComplex())
+	})
+
+	t.Run("does not fold scale depending on aspect ratio", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		var n INode = NewScaleFilterNode(i, 400, 400, true)
+		n = NewScaleFilterNode(n, 200, -2, false)
+
+		cmd := optimizedSelect(&Optimizer{Folding: true}, []INode{n})
+		require.Equal(t, `[0:0]scale=400:400,setsar=1:1,scale=200:-2`, cmd.FilterComplex())
+	})
+
+	t.Run("multiplies speed and volume", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		var v INode = NewVideoSpeedFilter(i, 0.5)
+		v = NewVideoSpeedFilter(v, 0.5)
+		var a INode = NewVolumeFilter(NewSelectStreamNode(i, AudioStream), 2)
+		a = NewVolumeFilter(a, 0.25)
+
+		cmd := optimizedSelect(&Optimizer{Folding: true}, []INode{v, a})
+		require.Equal(t, `[0:a]volume=0.50;[0:0]setpts=0.25*PTS`, cmd.FilterComplex())
+	})
+
+	t.Run("does not fold volume which cannot be rendered", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		var a INode = NewVolumeFilter(NewSelectStreamNode(i, AudioStream), 0.05)
+		a = NewVolumeFilter(a, 0.05)
+
+		cmd := optimizedSelect(&Optimizer{Folding: true}, []INode{a})
+		require.Equal(t, `[0:a]volume=0.05,volume=0.05`, cmd.FilterComplex())
+	})
+
+	t.Run("does not fold shared nodes", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		s := NewScaleFilterNode(i, 400, 400, false)
+		ov := NewOverlayIntoMiddleFilterNode(s, NewScaleFilterNode(s, 100, 100, false))
+
+		cmd := optimizedSelect(&Optimizer{Folding: true}, []INode{ov})
+		require.Contains(t, cmd.FilterComplex(), `scale=400:400,split`)
+		require.Contains(t, cmd.FilterComplex(), `scale=100:100`)
+	})
+}
+
+func TestOptimizerCommonSubexpressionElimination(t *testing.T) {
+	build := func() INode {
+		return NewBoxBlurFilter(NewScaleFilterNode(NewInputNode("vid.mp4", nil, nil), 200, 200, true), "5", "5", 1)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		ov := NewOverlayIntoMiddleFilterNode(build(), NewScaleFilterNode(build(), 100, 100, true))
+		cmd := Select([]INode{ov}, "out.mp4", nil)
+		require.Equal(t, 2, countOccurrences(cmd, "-i"))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		ov := NewOverlayIntoMiddleFilterNode(build(), NewScaleFilterNode(build(), 100, 100, true))
+		cmd := optimizedSelect(&Optimizer{CommonSubexpressionElimination: true}, []INode{ov})
+		require.Equal(t, 1, countOccurrences(cmd, "-i"))
+
+		reg := regexp.MustCompile(`^\[0:0]scale=200:200,setsar=1:1,boxblur=luma_radius=5:chroma_radius=5:luma_power=1,split(?P<s1>\[.*])(?P<s2>\[.*]);(?P<s2_2>\[.*])scale=100:100,setsar=1:1(?P<s3>\[.*]);(?P<s1_2>\[.*])(?P<s3_2>\[.*])overlay`)
+		require.Regexp(t, reg, cmd.FilterComplex())
+		params := getParams(reg, cmd.FilterComplex())
+		require.Equal(t, params["s1"], params["s1_2"])
+		require.Equal(t, params["s2"], params["s2_2"])
+		require.Equal(t, params["s3"], params["s3_2"])
+	})
+
+	t.Run("does not merge readers", func(t *testing.T) {
+		first, err := NewReaderInputNode(strings.NewReader("first"), InputOptions{Format: "mp4"})
+		require.NoError(t, err)
+		second, err := NewReaderInputNode(strings.NewReader("second"), InputOptions{Format: "mp4"})
+		require.NoError(t, err)
+
+		exec := NewFfmpegExecutor(nil, "out.mp4", nil)
+		exec.SetOptimizer(&Optimizer{CommonSubexpressionElimination: true})
+		cmd := exec.ToFfmpeg(NewOverlayIntoMiddleFilterNode(NewScaleFilterNode(first, 100, 100, false), NewScaleFilterNode(second, 100, 100, false)))
+		require.Equal(t, []string{"-f", "mp4", "-i", "pipe:0", "-f", "mp4", "-i", "pipe:3"}, []string(cmd[:8]))
+		require.Len(t, exec.Pipes().ExtraFiles, 1)
+	})
+}
+
+func countOccurrences(cmd FfmpegCommand, arg string) int {
+	res := 0
+	for _, a := range cmd {
+		if a == arg {
+			res++
+		}
+	}
+
+	return res
+}
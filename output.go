@@ -0,0 +1,13 @@
+package ffmpegtree
+
+// IOutput is an output which needs muxer options along with its name, such as segmenting muxers. It is written after
+// output options of the executor.
+type IOutput interface {
+	// ToString returns muxer options followed by the output name.
+	ToString() []string
+}
+
+// SetOutput sets the output which replaces the output name of the executor.
+func (e *FFmpegExecutor) SetOutput(o IOutput) {
+	e.output = o
+}
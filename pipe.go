@@ -0,0 +1,65 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"io"
+)
+
+// ReaderInputNode implements IInputNode
+var _ IInputNode = &ReaderInputNode{}
+
+// ReaderInputNode is an input read from an io.Reader through a pipe. The first one in a command is fed through stdin and
+// the others through extra file descriptors, which are wired by Run. Since format of a pipe cannot always be probed,
+// it is better to set it in options.
+type ReaderInputNode struct {
+	InputNode
+	Reader io.Reader
+}
+
+func (r *ReaderInputNode) setFd(fd int) {
+	r.InputName = fmt.Sprintf("pipe:%v", fd)
+}
+
+func NewReaderInputNode(r io.Reader, opts InputOptions) (*ReaderInputNode, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &ReaderInputNode{
+		InputNode: InputNode{
+			BaseNode:  NewBaseNode(nil),
+			InputName: "pipe:0",
+			Options:   opts,
+		},
+		Reader: r,
+	}, nil
+}
+
+// Pipes are the streams which should be wired to an ffmpeg process for a command.
+type Pipes struct {
+	// Stdin feeds 'pipe:0'.
+	Stdin io.Reader
+
+	// ExtraFiles feed 'pipe:3', 'pipe:4' and so on.
+	ExtraFiles []io.Reader
+
+	// Stdout is written by 'pipe:1'.
+	Stdout io.Writer
+}
+
+// pipeOutputOptions returns options required to write format to a pipe. Muxers which seek back to write their index,
+// such as mp4, are switched to fragmented output unless movflags are already given.
+func pipeOutputOptions(format string, outOptions []string) []string {
+	res := []string{"-f", format}
+	switch format {
+	case "mp4", "mov", "ipod", "ismv", "3gp":
+		for _, opt := range outOptions {
+			if opt == "-movflags" {
+				return res
+			}
+		}
+		res = append(res, "-movflags", "frag_keyframe+empty_moov+default_base_moof")
+	}
+
+	return res
+}
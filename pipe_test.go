@@ -0,0 +1,80 @@
+package ffmpegtree
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipes(t *testing.T) {
+	first, err := NewReaderInputNode(strings.NewReader("first"), InputOptions{Format: "mp4"})
+	require.NoError(t, err)
+	second, err := NewReaderInputNode(strings.NewReader("second"), InputOptions{Format: "wav"})
+	require.NoError(t, err)
+	file := NewInputNode("vid.mp4", nil, nil)
+
+	out := &bytes.Buffer{}
+	exec := NewFfmpegExecutor([]IMap{NewMap(second, "a")}, "", nil)
+	exec.SetOutputWriter(out, "mp4")
+	v := NewOverlayIntoMiddleFilterNode(first, file)
+	cmd := exec.ToFfmpeg(v)
+
+	require.Equal(t, []string{"-f", "mp4", "-i", "pipe:0", "-i", "vid.mp4", "-f", "wav", "-i", "pipe:3"}, []string(cmd[:10]))
+	require.Equal(t, []string{"-map", "2:a", "-f", "mp4", "-movflags", "frag_keyframe+empty_moov+default_base_moof", "pipe:1"}, []string(cmd[12:]))
+
+	t.Run("run", func(t *testing.T) {
+		// a fake ffmpeg which writes its inputs to its output
+		dir := t.TempDir()
+		fake := filepath.Join(dir, "ffmpeg")
+		require.NoError(t, os.WriteFile(fake, []byte("#!/bin/sh\ncat <&0\ncat <&3\n"), 0755))
+		defer func(p string) { FfmpegPath = p }(FfmpegPath)
+		FfmpegPath = fake
+
+		require.NoError(t, Run(context.Background(), cmd, exec.Pipes()))
+		require.Equal(t, "firstsecond", out.String())
+	})
+
+	t.Run("run does not wait for readers", func(t *testing.T) {
+		dir := t.TempDir()
+		fake := filepath.Join(dir, "ffmpeg")
+		require.NoError(t, os.WriteFile(fake, []byte("#!/bin/sh\nexit 0\n"), 0755))
+		defer func(p string) { FfmpegPath = p }(FfmpegPath)
+		FfmpegPath = fake
+
+		// readers which never return anything
+		stdin, stdinW := io.Pipe()
+		extra, extraW := io.Pipe()
+		defer stdinW.Close()
+		defer extraW.Close()
+
+		done := make(chan error, 1)
+		go func() { done <- Run(context.Background(), cmd, Pipes{Stdin: stdin, ExtraFiles: []io.Reader{extra}}) }()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("run did not return after ffmpeg exited")
+		}
+	})
+
+	t.Run("optimized", func(t *testing.T) {
+		first, err := NewReaderInputNode(strings.NewReader("first"), InputOptions{Format: "mp4"})
+		require.NoError(t, err)
+		second, err := NewReaderInputNode(strings.NewReader("second"), InputOptions{Format: "mp4"})
+		require.NoError(t, err)
+
+		exec := NewFfmpegExecutor(nil, "out.mp4", nil)
+		exec.SetOptimizer(&Optimizer{DeadNodeElimination: true, CommonSubexpressionElimination: true, Folding: true})
+		cmd := exec.ToFfmpeg(NewHStackFilter(first, second))
+
+		require.Equal(t, []string{"-f", "mp4", "-i", "pipe:0", "-f", "mp4", "-i", "pipe:3"}, []string(cmd[:8]))
+		require.Equal(t, Pipes{Stdin: first.Reader, ExtraFiles: []io.Reader{second.Reader}}, exec.Pipes())
+	})
+}
@@ -0,0 +1,133 @@
+package ffmpegtree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FfprobePath is the ffprobe binary used by Probe.
+var FfprobePath = "ffprobe"
+
+// ProbeResult is the subset of ffprobe's json output which is used by the library.
+type ProbeResult struct {
+	Streams []ProbeStream `json:"streams"`
+	Format  ProbeFormat   `json:"format"`
+}
+
+type ProbeStream struct {
+	Index             int               `json:"index"`
+	CodecType         string            `json:"codec_type"`
+	CodecName         string            `json:"codec_name"`
+	Width             int               `json:"width"`
+	Height            int               `json:"height"`
+	SampleAspectRatio string            `json:"sample_aspect_ratio"`
+	AvgFrameRate      string            `json:"avg_frame_rate"`
+	RFrameRate        string            `json:"r_frame_rate"`
+	SampleRate        string            `json:"sample_rate"`
+	Channels          int               `json:"channels"`
+	Duration          string            `json:"duration"`
+	Tags              map[string]string `json:"tags"`
+	Disposition       map[string]int    `json:"disposition"`
+}
+
+type ProbeFormat struct {
+	FormatName string            `json:"format_name"`
+	Duration   string            `json:"duration"`
+	Tags       map[string]string `json:"tags"`
+}
+
+// Probe runs ffprobe on the given input and parses its output.
+func Probe(name string) (*ProbeResult, error) {
+	out, err := exec.Command(FfprobePath, "-v", "error", "-show_format", "-show_streams", "-of", "json", name).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe %v: %w", name, err)
+	}
+
+	return ParseProbe(out)
+}
+
+// ParseProbe parses json output of 'ffprobe -show_format -show_streams -of json'.
+func ParseProbe(data []byte) (*ProbeResult, error) {
+	res := &ProbeResult{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+
+	return res, nil
+}
+
+// Stream returns the stream selected by a stream index or by 'v' and 'a' for first video and audio streams.
+func (p *ProbeResult) Stream(spec string) (ProbeStream, bool) {
+	codecType := ""
+	switch spec {
+	case "v":
+		codecType = "video"
+	case "a":
+		codecType = "audio"
+	default:
+		idx, err := strconv.Atoi(spec)
+		if err != nil {
+			return ProbeStream{}, false
+		}
+		for _, s := range p.Streams {
+			if s.Index == idx {
+				return s, true
+			}
+		}
+		return ProbeStream{}, false
+	}
+
+	for _, s := range p.Streams {
+		if s.CodecType == codecType {
+			return s, true
+		}
+	}
+
+	return ProbeStream{}, false
+}
+
+// parseRational parses ratios in the form of '30000/1001' or '16:9'.
+func parseRational(r string) (float64, bool) {
+	sep := "/"
+	if strings.Contains(r, ":") {
+		sep = ":"
+	}
+
+	parts := strings.Split(r, sep)
+	if len(parts) != 2 {
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	den, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || den == 0 || num == 0 {
+		return 0, false
+	}
+
+	return num / den, true
+}
+
+// parseFrameRate parses frame rates given either as a ratio or as a number.
+func parseFrameRate(r string) (float64, bool) {
+	if f, err := strconv.ParseFloat(r, 64); err == nil && f > 0 {
+		return f, true
+	}
+
+	return parseRational(r)
+}
+
+// parseSeconds parses durations in seconds as printed by ffprobe.
+func parseSeconds(s string) (time.Duration, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(f * float64(time.Second)), true
+}
@@ -0,0 +1,91 @@
+package ffmpegtree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// FfmpegPath is the ffmpeg binary used by Run.
+var FfmpegPath = "ffmpeg"
+
+// Run runs ffmpeg with the command and wires pipes to it. Stdin and extra files are copied into pipes in their own
+// goroutines, which are not waited for once ffmpeg exits, since a reader may block forever.
+func Run(ctx context.Context, cmd FfmpegCommand, pipes Pipes) error {
+	c := exec.CommandContext(ctx, FfmpegPath, cmd...)
+	c.Stdout = pipes.Stdout
+	stderr := &bytes.Buffer{}
+	c.Stderr = stderr
+
+	// stdin is copied like extra files, since exec would wait for its copy to end
+	readers := pipes.ExtraFiles
+	if pipes.Stdin != nil {
+		readers = append([]io.Reader{pipes.Stdin}, readers...)
+	}
+
+	readEnds := make([]*os.File, 0, len(readers))
+	writers := make([]*os.File, 0, len(readers))
+	closeAll := func(files []*os.File) {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	for range readers {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeAll(readEnds)
+			closeAll(writers)
+			return fmt.Errorf("create pipe: %w", err)
+		}
+		readEnds = append(readEnds, r)
+		writers = append(writers, w)
+	}
+	if pipes.Stdin != nil {
+		c.Stdin, c.ExtraFiles = readEnds[0], readEnds[1:]
+	} else {
+		c.ExtraFiles = readEnds
+	}
+
+	if err := c.Start(); err != nil {
+		closeAll(readEnds)
+		closeAll(writers)
+		return fmt.Errorf("start ffmpeg: %w", err)
+	}
+	// read ends belong to ffmpeg now
+	closeAll(readEnds)
+
+	copyErrs := make(chan error, len(writers))
+	for i, w := range writers {
+		go func(r io.Reader, w *os.File) {
+			_, err := io.Copy(w, r)
+			copyErrs <- err
+			w.Close()
+		}(readers[i], w)
+	}
+
+	err := c.Wait()
+	// nothing reads the pipes anymore. copies which wait for ffmpeg to read are ended by closing them, but the ones
+	// which wait for their reader cannot be interrupted and are left behind
+	closeAll(writers)
+
+	if err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, stderr.String())
+	}
+
+	for {
+		select {
+		case copyErr := <-copyErrs:
+			// ffmpeg may stop reading an input before its end, such as when it is cut
+			if copyErr != nil && !errors.Is(copyErr, syscall.EPIPE) && !errors.Is(copyErr, os.ErrClosed) {
+				return fmt.Errorf("copy input: %w", copyErr)
+			}
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LavfiSource describes one of libavfilter's source filters such as color or sine. It can be used either as an input
+// with '-f lavfi -i' or as a filter without inputs in the filter graph.
+type LavfiSource struct {
+	Name string
+
+	// Options are in the form of 'key=value' and their values should already be escaped.
+	Options []string
+
+	// info is what is known about the generated stream
+	info StreamInfo
+}
+
+func (s LavfiSource) String() string {
+	if len(s.Options) == 0 {
+		return s.Name
+	}
+
+	return s.Name + "=" + strings.Join(s.Options, ":")
+}
+
+// AsInput returns an input node which opens the source with '-f lavfi -i'.
+func (s LavfiSource) AsInput() *LavfiInputNode {
+	return &LavfiInputNode{
+		BaseNode: NewBaseNode(nil),
+		Source:   s,
+	}
+}
+
+// AsFilter returns a filter node without any inputs which generates the stream inside the filter graph.
+func (s LavfiSource) AsFilter() *SourceFilterNode {
+	return &SourceFilterNode{
+		BaseFilterNode: *NewBaseFilterNode(nil, randStr()),
+		Source:         s,
+	}
+}
+
+// NewColorSource generates a stream of the given color. duration can be nil for an infinite stream and rate can be 0
+// for the default frame rate.
+func NewColorSource(color string, w, h, rate int, duration *time.Duration) LavfiSource {
+	return newVideoSource("color", []string{"c=" + escapeFilterArg(color)}, w, h, rate, duration)
+}
+
+// NewTestSource generates testsrc2 test pattern.
+func NewTestSource(w, h, rate int, duration *time.Duration) LavfiSource {
+	return newVideoSource("testsrc2", nil, w, h, rate, duration)
+}
+
+// NewSmpteBarsSource generates color bars pattern.
+func NewSmpteBarsSource(w, h, rate int, duration *time.Duration) LavfiSource {
+	return newVideoSource("smptebars", nil, w, h, rate, duration)
+}
+
+func newVideoSource(name string, opts []string, w, h, rate int, duration *time.Duration) LavfiSource {
+	s := LavfiSource{Name: name, Options: opts}
+	if w > 0 && h > 0 {
+		s.Options = append(s.Options, fmt.Sprintf("s=%vx%v", w, h))
+		s.info.Width, s.info.Height, s.info.SAR = intPtr(w), intPtr(h), float64Ptr(1)
+	}
+	if rate > 0 {
+		s.Options = append(s.Options, fmt.Sprintf("r=%v", rate))
+		s.info.FPS = float64Ptr(float64(rate))
+	}
+	if duration != nil {
+		s.Options = append(s.Options, "d="+fmtSeconds(*duration))
+		d := *duration
+		s.info.Duration = &d
+	}
+
+	return s
+}
+
+// NewAnullSource generates silent audio. channelLayout can be empty for the default one.
+func NewAnullSource(sampleRate int, channelLayout string, duration *time.Duration) LavfiSource {
+	s := LavfiSource{Name: "anullsrc"}
+	if channelLayout != "" {
+		s.Options = append(s.Options, "cl="+channelLayout)
+	}
+
+	return withAudioOptions(s, sampleRate, duration)
+}
+
+// NewSineSource generates a sine wave of the given frequency.
+func NewSineSource(frequency float64, sampleRate int, duration *time.Duration) LavfiSource {
+	s := LavfiSource{Name: "sine", Options: []string{fmt.Sprintf("f=%v", frequency)}}
+	return withAudioOptions(s, sampleRate, duration)
+}
+
+func withAudioOptions(s LavfiSource, sampleRate int, duration *time.Duration) LavfiSource {
+	if sampleRate > 0 {
+		s.Options = append(s.Options, fmt.Sprintf("r=%v", sampleRate))
+		s.info.SampleRate = intPtr(sampleRate)
+	}
+	if duration != nil {
+		s.Options = append(s.Options, "d="+fmtSeconds(*duration))
+		d := *duration
+		s.info.Duration = &d
+	}
+
+	return s
+}
+
+// NewMovieSource reads an image or a video file with the movie filter. Images are repeated forever if loop is true.
+func NewMovieSource(path string, loop bool) LavfiSource {
+	s := LavfiSource{Name: "movie", Options: []string{"filename=" + escapeFilterArg(path)}}
+	if loop {
+		s.Options = append(s.Options, "loop=0")
+	}
+
+	return s
+}
+
+// SourceFilterNode is a filter without any inputs which generates a stream.
+type SourceFilterNode struct {
+	BaseFilterNode
+	Source LavfiSource
+}
+
+func (s *SourceFilterNode) FilterString() string {
+	return s.Source.String()
+}
+
+func (s *SourceFilterNode) PropagateInfo([]StreamInfo) StreamInfo {
+	return s.Source.info
+}
+
+// LavfiInputNode implements IInputNode
+var _ IInputNode = &LavfiInputNode{}
+
+// LavfiInputNode is an input which is generated by a source filter with '-f lavfi -i'.
+type LavfiInputNode struct {
+	BaseNode
+	Source   LavfiSource
+	inputIdx int
+}
+
+func (l *LavfiInputNode) ToString() []string {
+	return []string{"-f", "lavfi", "-i", l.Source.String()}
+}
+
+func (l *LavfiInputNode) GetInputIdx() int {
+	return l.inputIdx
+}
+
+func (l *LavfiInputNode) SetInputIdx(idx int) {
+	l.inputIdx = idx
+}
+
+func (l *LavfiInputNode) GetInputName() string {
+	return l.Source.String()
+}
+
+func (l *LavfiInputNode) StreamInfo(string) StreamInfo {
+	return l.Source.info
+}
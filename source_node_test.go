@@ -0,0 +1,53 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLavfiSources(t *testing.T) {
+	d := 5 * time.Second
+
+	t.Run("as inputs", func(t *testing.T) {
+		slate := NewColorSource("black", 1280, 720, 25, &d).AsInput()
+		silence := NewAnullSource(44100, "stereo", &d).AsInput()
+		s := NewScaleFilterNode(slate, 640, 360, false)
+
+		cmd := Select([]INode{s}, "out.mp4", nil, NewMap(silence, "a"))
+		require.Equal(t, FfmpegCommand{
+			"-f", "lavfi", "-i", "color=c=black:s=1280x720:r=25:d=5",
+			"-f", "lavfi", "-i", "anullsrc=cl=stereo:r=44100:d=5",
+			"-filter_complex", "[0:0]scale=640:360",
+			"-map", "1:a",
+			"out.mp4",
+		}, cmd)
+	})
+
+	t.Run("as filters", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		bars := NewSmpteBarsSource(1920, 1080, 30, &d).AsFilter()
+		logo := NewMovieSource("C:\\logos\\it's [a] logo.png", true).AsFilter()
+		ov := NewOverlayIntoMiddleFilterNode(NewOverlayIntoMiddleFilterNode(bars, i), logo)
+		tone := NewSineSource(440, 48000, nil).AsFilter()
+
+		cmd := Select([]INode{ov, tone}, "out.mp4", nil, NewMap(ov), NewMap(tone))
+		require.Equal(t, "-i", cmd[0])
+		require.Contains(t, cmd.FilterComplex(), `movie=filename=C\\:\\\\logos\\\\it\\\'s \[a\] logo.png:loop=0[var_`)
+		require.Contains(t, cmd.FilterComplex(), `smptebars=s=1920x1080:r=30:d=5[var_`)
+		require.Contains(t, cmd.FilterComplex(), `;sine=f=440:r=48000[var_`)
+
+		info := Info(ov)
+		require.Equal(t, 1920, *info.Width)
+		require.Equal(t, d, *info.Duration)
+	})
+
+	t.Run("input as root", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		tone := NewSineSource(440, 0, nil).AsInput()
+
+		Select([]INode{NewScaleFilterNode(i, 100, 100, false), tone}, "out.mp4", nil)
+		require.Equal(t, 1, tone.GetInputIdx())
+	})
+}
@@ -0,0 +1,345 @@
+package ffmpegtree
+
+import (
+	"strconv"
+	"time"
+)
+
+// StreamInfo describes properties of a stream at a node in the graph. Nil fields are unknown, either because the input
+// is not probed or because a filter on the way is opaque.
+type StreamInfo struct {
+	Width, Height *int
+	SAR           *float64
+	FPS           *float64
+	Duration      *time.Duration
+	SampleRate    *int
+}
+
+// InfoPropagator is implemented by nodes which know how they change properties of their input streams.
+type InfoPropagator interface {
+	PropagateInfo(inputs []StreamInfo) StreamInfo
+}
+
+// InputInfoProvider is implemented by input nodes which can describe their streams. stream is either a stream index or
+// 'v' and 'a' for first video and audio streams.
+type InputInfoProvider interface {
+	StreamInfo(stream string) StreamInfo
+}
+
+// Info statically propagates stream properties from inputs through the graph and returns them for the given node.
+func Info(node INode) StreamInfo {
+	return info(node, make(map[string]StreamInfo))
+}
+
+func info(t INode, memo map[string]StreamInfo) StreamInfo {
+	if res, ok := memo[t.GetID()]; ok {
+		return res
+	}
+
+	res := StreamInfo{}
+	switch n := t.(type) {
+	case InputInfoProvider:
+		// an input which is directly fed into a filter is its first stream, see insertSelectStream
+		res = n.StreamInfo("0")
+	case InfoPropagator:
+		inputs := make([]StreamInfo, 0, len(t.GetInputs()))
+		for _, inp := range t.GetInputs() {
+			inputs = append(inputs, info(inp, memo))
+		}
+		res = n.PropagateInfo(inputs)
+	}
+
+	memo[t.GetID()] = res
+	return res
+}
+
+func (i *InputNode) StreamInfo(stream string) StreamInfo {
+	res := StreamInfo{}
+	if i.Metadata == nil {
+		if i.Len != nil {
+			// without metadata a cut input is assumed to be at least as long as the cut
+			d := *i.Len
+			res.Duration = &d
+		}
+		return res
+	}
+
+	s, ok := i.Metadata.Stream(stream)
+	if !ok {
+		return res
+	}
+
+	if s.Width > 0 && s.Height > 0 {
+		res.Width, res.Height = intPtr(s.Width), intPtr(s.Height)
+	}
+	if sar, ok := parseRational(s.SampleAspectRatio); ok {
+		res.SAR = &sar
+	} else if res.Width != nil {
+		res.SAR = float64Ptr(1)
+	}
+	if fps, ok := parseRational(s.AvgFrameRate); ok {
+		res.FPS = &fps
+	} else if fps, ok := parseRational(s.RFrameRate); ok {
+		res.FPS = &fps
+	}
+	if res.FPS != nil && i.Options.FrameRate != "" {
+		// frame rate forced on the input overrides the probed one
+		if fps, ok := parseFrameRate(i.Options.FrameRate); ok {
+			res.FPS = &fps
+		}
+	}
+	if sr, err := strconv.Atoi(s.SampleRate); err == nil && sr > 0 {
+		res.SampleRate = &sr
+	}
+
+	d, ok := parseSeconds(s.Duration)
+	if !ok {
+		d, ok = parseSeconds(i.Metadata.Format.Duration)
+	}
+	if ok && i.Offset != nil {
+		d -= *i.Offset
+		if d < 0 {
+			d = 0
+		}
+	}
+	if i.isLoop {
+		// looped inputs are infinite unless they are cut
+		ok = false
+	}
+	if i.Len != nil && (!ok || *i.Len < d) {
+		d, ok = *i.Len, true
+	}
+	if ok {
+		res.Duration = &d
+	}
+
+	return res
+}
+
+func (s *SelectStreamNode) PropagateInfo([]StreamInfo) StreamInfo {
+	if p, ok := s.input.(InputInfoProvider); ok {
+		return p.StreamInfo(s.idx)
+	}
+
+	return StreamInfo{}
+}
+
+func (b *ScaleFilterNode) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	in := inputs[0]
+	res := in
+
+	w, h, ok := scaledSize(b.W, b.H, in.Width, in.Height)
+	if ok && b.ForceOriginalAspectRatio != "" {
+		w, h, ok = fittedSize(w, h, b.ForceOriginalAspectRatio == "increase", in.Width, in.Height)
+	}
+	if !ok {
+		res.Width, res.Height, res.SAR = nil, nil, nil
+	} else {
+		res.Width, res.Height = intPtr(w), intPtr(h)
+		if in.SAR != nil && in.Width != nil && in.Height != nil {
+			// scale keeps display aspect ratio by adjusting sample aspect ratio
+			sar := *in.SAR * float64(h**in.Width) / float64(w**in.Height)
+			res.SAR = &sar
+		} else {
+			res.SAR = nil
+		}
+	}
+
+	if b.SetSar {
+		res.SAR = float64Ptr(1)
+	}
+
+	return res
+}
+
+// scaledSize calculates output size of the scale filter the same way ffmpeg does. 0 keeps input dimension, -1 keeps
+// aspect ratio and -n keeps aspect ratio while making the dimension divisible by n.
+func scaledSize(w, h int, inW, inH *int) (int, int, bool) {
+	if w > 0 && h > 0 {
+		return w, h, true
+	}
+	if inW == nil || inH == nil || *inW == 0 || *inH == 0 {
+		return 0, 0, false
+	}
+
+	if w == 0 {
+		w = *inW
+	}
+	if h == 0 {
+		h = *inH
+	}
+
+	factorW, factorH := 1, 1
+	if w < -1 {
+		factorW = -w
+	}
+	if h < -1 {
+		factorH = -h
+	}
+
+	if w < 0 && h < 0 {
+		w, h = *inW, *inH
+	}
+	if w < 0 {
+		w = rescale(h, *inW, *inH*factorW) * factorW
+	}
+	if h < 0 {
+		h = rescale(w, *inH, *inW*factorH) * factorH
+	}
+
+	return w, h, true
+}
+
+// fittedSize calculates the size which keeps the input's aspect ratio and fits inside w x h, or covers it if increase
+// is set, the same way ffmpeg does with force_original_aspect_ratio.
+func fittedSize(w, h int, increase bool, inW, inH *int) (int, int, bool) {
+	if inW == nil || inH == nil || *inW == 0 || *inH == 0 {
+		return 0, 0, false
+	}
+
+	keepW, keepH := rescale(h, *inW, *inH), rescale(w, *inH, *inW)
+	if (keepW < w) != increase {
+		w = keepW
+	}
+	if (keepH < h) != increase {
+		h = keepH
+	}
+
+	return w, h, true
+}
+
+// rescale returns a*b/c rounded to the nearest integer
+func rescale(a, b, c int) int {
+	return (a*b + c/2) / c
+}
+
+func (f *PadFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	res.Width, res.Height = intPtr(f.W), intPtr(f.H)
+	return res
+}
+
+func (s *CropFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	res.Width, res.Height = nil, nil
+	if s.Width > 0 && s.Height > 0 {
+		res.Width, res.Height = intPtr(s.Width), intPtr(s.Height)
+	}
+
+	return res
+}
+
+func (s *FpsFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	res.FPS = float64Ptr(float64(s.fps))
+	return res
+}
+
+func (s *VideoSpeedFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	k := float64(s.PresentationTimeStamps)
+	if res.Duration != nil {
+		d := time.Duration(float64(*res.Duration) * k)
+		res.Duration = &d
+	}
+	if res.FPS != nil {
+		res.FPS = float64Ptr(*res.FPS / k)
+	}
+
+	return res
+}
+
+func (f *AtempoFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	if res.Duration != nil {
+		d := time.Duration(float64(*res.Duration) / float64(f.speed))
+		res.Duration = &d
+	}
+
+	return res
+}
+
+func (n *OverlayFilterNode) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	// output has properties of the main input and ends with it
+	return inputs[0]
+}
+
+func (n *OverlayIntoMiddleFilterNode) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	return inputs[0]
+}
+
+func (f *ConcatFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+
+	var total time.Duration
+	for _, in := range inputs {
+		if in.Duration == nil {
+			res.Duration = nil
+			return res
+		}
+		total += *in.Duration
+	}
+	res.Duration = &total
+
+	return res
+}
+
+func (f *TrimFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+
+	var d *time.Duration
+	if res.Duration != nil {
+		rest := *res.Duration
+		if f.Start != nil {
+			rest -= *f.Start
+		}
+		if rest < 0 {
+			rest = 0
+		}
+		d = &rest
+	}
+	if f.Duration != nil && (d == nil || *f.Duration < *d) {
+		dur := *f.Duration
+		d = &dur
+	}
+	res.Duration = d
+
+	return res
+}
+
+func (f *XfadeFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	res.Duration = nil
+	if inputs[1].Duration != nil {
+		d := f.Offset + *inputs[1].Duration
+		res.Duration = &d
+	}
+
+	return res
+}
+
+func (f *AformatFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := inputs[0]
+	res.SampleRate = intPtr(44100)
+	return res
+}
+
+// filters below do not change properties of their inputs
+
+func (s *SplitNode) PropagateInfo(inputs []StreamInfo) StreamInfo        { return inputs[0] }
+func (n *ChromaFilterNode) PropagateInfo(inputs []StreamInfo) StreamInfo { return inputs[0] }
+func (f *DrawBoxFilter) PropagateInfo(inputs []StreamInfo) StreamInfo    { return inputs[0] }
+func (f *BoxBlurFilter) PropagateInfo(inputs []StreamInfo) StreamInfo    { return inputs[0] }
+func (f *CurvesFilter) PropagateInfo(inputs []StreamInfo) StreamInfo     { return inputs[0] }
+func (f *RotateFilter) PropagateInfo(inputs []StreamInfo) StreamInfo     { return inputs[0] }
+func (f *DrawTextFilter) PropagateInfo(inputs []StreamInfo) StreamInfo   { return inputs[0] }
+func (s *VolumeFilter) PropagateInfo(inputs []StreamInfo) StreamInfo     { return inputs[0] }
+func (s *AechoFilter) PropagateInfo(inputs []StreamInfo) StreamInfo      { return inputs[0] }
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,74 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testProbe = `{
+	"streams": [
+		{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "sample_aspect_ratio": "1:1", "avg_frame_rate": "30/1", "duration": "10.000000"},
+		{"index": 1, "codec_type": "audio", "codec_name": "aac", "sample_rate": "48000", "channels": 2, "duration": "10.000000"}
+	],
+	"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "10.000000"}
+}`
+
+func probedInput(t *testing.T) *InputNode {
+	p, err := ParseProbe([]byte(testProbe))
+	require.NoError(t, err)
+
+	i := NewInputNode("vid.mp4", nil, nil)
+	i.Metadata = p
+	return i
+}
+
+func TestInfo(t *testing.T) {
+	t.Run("scale keeping aspect ratio", func(t *testing.T) {
+		i := probedInput(t)
+		s := NewScaleFilterNode(i, 500, -2, false)
+
+		info := Info(s)
+		require.Equal(t, 500, *info.Width)
+		require.Equal(t, 282, *info.Height)
+		require.InDelta(t, 1.0, *info.SAR, 0.01)
+		require.Equal(t, 30.0, *info.FPS)
+	})
+
+	t.Run("speed, trim and concat", func(t *testing.T) {
+		i := probedInput(t)
+		fast := NewVideoSpeedFilter(i, 0.5)
+		start := 2 * time.Second
+		trimmed := NewTrimFilter(i, &start, nil)
+		c := NewConcatFilter(fast, trimmed)
+
+		require.Equal(t, 5*time.Second, *Info(fast).Duration)
+		require.Equal(t, 60.0, *Info(fast).FPS)
+		require.Equal(t, 8*time.Second, *Info(trimmed).Duration)
+		require.Equal(t, 13*time.Second, *Info(c).Duration)
+	})
+
+	t.Run("audio stream", func(t *testing.T) {
+		length := 4 * time.Second
+		i := probedInput(t)
+		i.Len = &length
+		a := NewAtempoFilter(NewSelectStreamNode(i, AudioStream), 2)
+
+		info := Info(a)
+		require.Equal(t, 48000, *info.SampleRate)
+		require.Equal(t, 2*time.Second, *info.Duration)
+		require.Nil(t, info.Width)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		s := NewScaleFilterNode(NewInputNode("vid.mp4", nil, nil), 500, -2, true)
+
+		info := Info(s)
+		require.Nil(t, info.Width)
+		require.Nil(t, info.Duration)
+		require.Equal(t, 1.0, *info.SAR)
+
+		require.Nil(t, Info(NewMergeNode(s, s)).Width)
+	})
+}
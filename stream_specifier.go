@@ -0,0 +1,216 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StreamSpecifier selects streams of an input, such as 'v:1', 'a:m:language:eng' or 'p:1:s'. Zero value selects all
+// streams.
+type StreamSpecifier struct {
+	// Type is one of 'v', 'V', 'a', 's', 'd' and 't', or empty for any type.
+	Type string
+
+	// Index is the index of the stream among streams of Type, or among all streams if Type is empty.
+	Index *int
+
+	// MetadataKey and MetadataValue select streams by their tags. Empty value matches any stream having the key.
+	MetadataKey, MetadataValue string
+
+	Program *int
+
+	// Optional makes a map to be ignored instead of failing if no stream matches. It is only valid for maps.
+	Optional bool
+}
+
+func AllStreams() StreamSpecifier      { return StreamSpecifier{} }
+func VideoStreams() StreamSpecifier    { return StreamSpecifier{Type: "v"} }
+func AudioStreams() StreamSpecifier    { return StreamSpecifier{Type: "a"} }
+func SubtitleStreams() StreamSpecifier { return StreamSpecifier{Type: "s"} }
+func DataStreams() StreamSpecifier     { return StreamSpecifier{Type: "d"} }
+
+// StreamAt selects the stream at the given index among all streams.
+func StreamAt(idx int) StreamSpecifier {
+	return StreamSpecifier{Index: &idx}
+}
+
+// Nth selects the stream at the given index among selected streams.
+func (s StreamSpecifier) Nth(idx int) StreamSpecifier {
+	s.Index = &idx
+	return s
+}
+
+// WithMetadata selects streams having the tag. Empty value matches any value.
+func (s StreamSpecifier) WithMetadata(key, value string) StreamSpecifier {
+	s.MetadataKey, s.MetadataValue = key, value
+	return s
+}
+
+// WithLanguage selects streams by their language tag such as 'eng'.
+func (s StreamSpecifier) WithLanguage(lang string) StreamSpecifier {
+	return s.WithMetadata("language", lang)
+}
+
+func (s StreamSpecifier) InProgram(id int) StreamSpecifier {
+	s.Program = &id
+	return s
+}
+
+func (s StreamSpecifier) AsOptional() StreamSpecifier {
+	s.Optional = true
+	return s
+}
+
+// String returns the specifier in ffmpeg's syntax, with a trailing '?' if it is optional.
+func (s StreamSpecifier) String() string {
+	res := s.spec()
+	if s.Optional {
+		res += "?"
+	}
+
+	return res
+}
+
+// spec returns the specifier without the optional mark, which is what is used in filter graphs.
+func (s StreamSpecifier) spec() string {
+	parts := make([]string, 0)
+	if s.Program != nil {
+		parts = append(parts, "p", strconv.Itoa(*s.Program))
+	}
+	if s.Type != "" {
+		parts = append(parts, s.Type)
+	}
+	if s.MetadataKey != "" {
+		parts = append(parts, "m", s.MetadataKey)
+		if s.MetadataValue != "" {
+			parts = append(parts, s.MetadataValue)
+		}
+	} else if s.Index != nil {
+		parts = append(parts, strconv.Itoa(*s.Index))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func (s StreamSpecifier) check() error {
+	switch s.Type {
+	case "", "v", "V", "a", "s", "d", "t":
+	default:
+		return fmt.Errorf("unknown stream type: %v", s.Type)
+	}
+
+	if s.MetadataKey != "" && s.Index != nil {
+		return fmt.Errorf("stream specifier cannot have both an index and metadata")
+	}
+	if s.MetadataKey == "" && s.MetadataValue != "" {
+		return fmt.Errorf("metadata value is given without a key")
+	}
+
+	return nil
+}
+
+// matching returns probed streams which are selected by the specifier. ok is false if it cannot be known, since
+// programs are not probed.
+func (s StreamSpecifier) matching(p *ProbeResult) (res []ProbeStream, ok bool) {
+	if s.Program != nil {
+		return nil, false
+	}
+
+	candidates := make([]ProbeStream, 0)
+	for _, ps := range p.Streams {
+		if s.matchesType(ps) {
+			candidates = append(candidates, ps)
+		}
+	}
+
+	if s.Index != nil {
+		if *s.Index < len(candidates) {
+			return candidates[*s.Index : *s.Index+1], true
+		}
+		return nil, true
+	}
+
+	for _, ps := range candidates {
+		if s.MetadataKey == "" {
+			res = append(res, ps)
+			continue
+		}
+		v, has := ps.Tags[s.MetadataKey]
+		if has && (s.MetadataValue == "" || v == s.MetadataValue) {
+			res = append(res, ps)
+		}
+	}
+
+	return res, true
+}
+
+func (s StreamSpecifier) matchesType(ps ProbeStream) bool {
+	switch s.Type {
+	case "":
+		return true
+	case "v":
+		return ps.CodecType == "video"
+	case "V":
+		return ps.CodecType == "video" && ps.Disposition["attached_pic"] == 0
+	case "a":
+		return ps.CodecType == "audio"
+	case "s":
+		return ps.CodecType == "subtitle"
+	case "d":
+		return ps.CodecType == "data"
+	case "t":
+		return ps.CodecType == "attachment"
+	}
+
+	return false
+}
+
+// validateStreamSpecifier checks the specifier and, if input is probed, that it selects at least one stream unless it
+// is optional.
+func validateStreamSpecifier(input IInputNode, s StreamSpecifier) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+
+	p, ok := input.(interface{ GetMetadata() *ProbeResult })
+	if !ok || p.GetMetadata() == nil || s.Optional {
+		return nil
+	}
+
+	streams, ok := s.matching(p.GetMetadata())
+	if ok && len(streams) == 0 {
+		return fmt.Errorf("no stream matches %v in %v", s, input.GetInputName())
+	}
+
+	return nil
+}
+
+// NewSelectStreamNodeBySpecifier selects streams of input in the filter graph. Optional specifiers are not allowed since
+// filter graphs cannot ignore missing streams.
+func NewSelectStreamNodeBySpecifier(input IInputNode, s StreamSpecifier) (*SelectStreamNode, error) {
+	if s.Optional {
+		return nil, fmt.Errorf("optional stream specifier cannot be used in filter graph: %v", s)
+	}
+	if err := validateStreamSpecifier(input, s); err != nil {
+		return nil, err
+	}
+
+	return &SelectStreamNode{
+		BaseNode: NewBaseNode([]INode{input}),
+		input:    input,
+		idx:      s.spec(),
+	}, nil
+}
+
+// NewStreamMap maps streams of input to the output.
+func NewStreamMap(input IInputNode, s StreamSpecifier) (IMap, error) {
+	if err := validateStreamSpecifier(input, s); err != nil {
+		return nil, err
+	}
+
+	return &MapFromInputNode{
+		input:  input,
+		stream: s.String(),
+	}, nil
+}
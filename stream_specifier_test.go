@@ -0,0 +1,57 @@
+package ffmpegtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testProbeWithLanguages = `{
+	"streams": [
+		{"index": 0, "codec_type": "video", "width": 1920, "height": 1080},
+		{"index": 1, "codec_type": "audio", "tags": {"language": "eng"}},
+		{"index": 2, "codec_type": "audio", "tags": {"language": "tur"}},
+		{"index": 3, "codec_type": "subtitle", "tags": {"language": "eng"}}
+	],
+	"format": {}
+}`
+
+func TestStreamSpecifier(t *testing.T) {
+	require.Equal(t, "v:1", VideoStreams().Nth(1).String())
+	require.Equal(t, "a:m:language:eng", AudioStreams().WithLanguage("eng").String())
+	require.Equal(t, "s?", SubtitleStreams().AsOptional().String())
+	require.Equal(t, "p:2:a", AudioStreams().InProgram(2).String())
+	require.Equal(t, "3", StreamAt(3).String())
+
+	p, err := ParseProbe([]byte(testProbeWithLanguages))
+	require.NoError(t, err)
+	i := NewInputNode("movie.mkv", nil, nil)
+	i.Metadata = p
+
+	t.Run("map", func(t *testing.T) {
+		tur, err := NewStreamMap(i, AudioStreams().WithLanguage("tur"))
+		require.NoError(t, err)
+		subs, err := NewStreamMap(i, SubtitleStreams().WithLanguage("fre").AsOptional())
+		require.NoError(t, err)
+
+		_, err = NewStreamMap(i, AudioStreams().WithLanguage("fre"))
+		require.Error(t, err)
+		_, err = NewStreamMap(i, AudioStreams().Nth(2))
+		require.Error(t, err)
+		_, err = NewStreamMap(i, StreamSpecifier{Type: "x"})
+		require.Error(t, err)
+
+		cmd := Select(nil, "out.mkv", []string{"-c", "copy"}, tur, subs)
+		require.Equal(t, FfmpegCommand{"-i", "movie.mkv", "-map", "0:a:m:language:tur", "-map", "0:s:m:language:fre?", "-c", "copy", "out.mkv"}, cmd)
+	})
+
+	t.Run("select", func(t *testing.T) {
+		eng, err := NewSelectStreamNodeBySpecifier(i, AudioStreams().WithLanguage("eng"))
+		require.NoError(t, err)
+		_, err = NewSelectStreamNodeBySpecifier(i, AudioStreams().AsOptional())
+		require.Error(t, err)
+
+		cmd := Select([]INode{NewVolumeFilter(eng, 2)}, "out.mkv", nil)
+		require.Equal(t, "[0:a:m:language:eng]volume=2.00", cmd.FilterComplex())
+	})
+}
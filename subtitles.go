@@ -0,0 +1,164 @@
+package ffmpegtree
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SubtitlesFilter burns subtitles from an srt or ass file into the video.
+type SubtitlesFilter struct {
+	BaseFilterNode
+	Path string
+
+	// ForceStyle overrides styles of subtitles in ass format, such as {"FontName": "Arial", "FontSize": "24"}.
+	ForceStyle map[string]string
+}
+
+func (f *SubtitlesFilter) FilterString() string {
+	res := "subtitles=filename=" + escapeFilterArg(f.Path)
+	if len(f.ForceStyle) == 0 {
+		return res
+	}
+
+	keys := make([]string, 0, len(f.ForceStyle))
+	for k := range f.ForceStyle {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	styles := make([]string, 0, len(keys))
+	for _, k := range keys {
+		styles = append(styles, fmt.Sprintf("%v=%v", k, f.ForceStyle[k]))
+	}
+
+	return res + ":force_style=" + escapeFilterArg(strings.Join(styles, ","))
+}
+
+func NewSubtitlesFilter(input INode, path string, forceStyle map[string]string) *SubtitlesFilter {
+	return &SubtitlesFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		Path:           path,
+		ForceStyle:     forceStyle,
+	}
+}
+
+func (f *SubtitlesFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	return inputs[0]
+}
+
+type SubtitleCodec string
+
+const (
+	MovText SubtitleCodec = "mov_text"
+	WebVTT  SubtitleCodec = "webvtt"
+	ASS     SubtitleCodec = "ass"
+	SRT     SubtitleCodec = "srt"
+)
+
+// AddSubtitleTrack maps subtitle streams of input selected by stream as soft subtitle tracks encoded with codec. Output
+// subtitle streams are counted by the executor, so subtitle streams should not be mapped in another way. If input is
+// not probed, stream should select a single stream which is not optional, since the number of mapped streams cannot be
+// known otherwise.
+func (e *FFmpegExecutor) AddSubtitleTrack(input IInputNode, stream StreamSpecifier, codec SubtitleCodec) error {
+	m, err := NewStreamMap(input, stream)
+	if err != nil {
+		return err
+	}
+	count, err := subtitleStreamCount(input, stream)
+	if err != nil {
+		return err
+	}
+
+	e.maps = append(e.maps, m)
+	for i := 0; i < count; i++ {
+		e.outputArgs = append(e.outputArgs, fmt.Sprintf("-c:s:%v", e.subtitleTracks), string(codec))
+		e.subtitleTracks++
+	}
+
+	return nil
+}
+
+// subtitleStreamCount returns the number of subtitle streams of input selected by stream.
+func subtitleStreamCount(input IInputNode, stream StreamSpecifier) (int, error) {
+	if p, ok := input.(interface{ GetMetadata() *ProbeResult }); ok && p.GetMetadata() != nil {
+		if streams, ok := stream.matching(p.GetMetadata()); ok {
+			count := 0
+			for _, ps := range streams {
+				if ps.CodecType == "subtitle" {
+					count++
+				}
+			}
+			return count, nil
+		}
+	}
+
+	if stream.Index == nil || stream.Optional {
+		return 0, fmt.Errorf("number of streams matching %v in %v is not known, input should be probed", stream, input.GetInputName())
+	}
+
+	return 1, nil
+}
+
+// Cue is a caption shown between Start and End.
+type Cue struct {
+	Start, End time.Duration
+	Text       string
+}
+
+// SRTCues returns cues in SubRip format.
+func SRTCues(cues []Cue) string {
+	b := &bytes.Buffer{}
+	for i, c := range cues {
+		fmt.Fprintf(b, "%v\n%v --> %v\n%v\n\n", i+1, fmtCueTime(c.Start, ","), fmtCueTime(c.End, ","), cueText(c.Text))
+	}
+
+	return b.String()
+}
+
+// WebVTTCues returns cues in WebVTT format.
+func WebVTTCues(cues []Cue) string {
+	b := &bytes.Buffer{}
+	b.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		text := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(c.Text)
+		fmt.Fprintf(b, "%v --> %v\n%v\n\n", fmtCueTime(c.Start, "."), fmtCueTime(c.End, "."), cueText(text))
+	}
+
+	return b.String()
+}
+
+// WriteSubtitles writes cues to path in SubRip format, or in WebVTT format if path ends with '.vtt'.
+func WriteSubtitles(path string, cues []Cue) error {
+	content := SRTCues(cues)
+	if strings.HasSuffix(path, ".vtt") {
+		content = WebVTTCues(cues)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write subtitles: %w", err)
+	}
+
+	return nil
+}
+
+// cueText drops empty lines, which would otherwise end the cue.
+func cueText(text string) string {
+	lines := make([]string, 0)
+	for _, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(l) != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// fmtCueTime formats durations as 'HH:MM:SS,mmm' where sep separates milliseconds.
+func fmtCueTime(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%v%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
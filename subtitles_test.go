@@ -0,0 +1,57 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubtitles(t *testing.T) {
+	t.Run("burn in", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		s := NewSubtitlesFilter(i, "/subs/it's: [final].srt", map[string]string{"FontSize": "24", "FontName": "Arial"})
+
+		cmd := Select([]INode{s}, "out.mp4", nil)
+		require.Equal(t, `[0:0]subtitles=filename=/subs/it\\\'s\\: \[final\].srt:force_style=FontName=Arial\,FontSize=24`, cmd.FilterComplex())
+	})
+
+	t.Run("soft tracks", func(t *testing.T) {
+		i, subs := NewInputNode("vid.mp4", nil, nil), NewInputNode("subs.srt", nil, nil)
+		exec := NewFfmpegExecutor([]IMap{NewMap(i, "v"), NewMap(i, "a")}, "out.mp4", []string{"-c:v", "copy", "-c:a", "copy"})
+		require.NoError(t, exec.AddSubtitleTrack(subs, SubtitleStreams().Nth(0), MovText))
+
+		// number of streams is not known without probing
+		require.Error(t, exec.AddSubtitleTrack(i, SubtitleStreams(), MovText))
+		require.Error(t, exec.AddSubtitleTrack(i, SubtitleStreams().Nth(0).AsOptional(), MovText))
+
+		// every matched stream gets its own codec, and an optional specifier may match none
+		mkv := NewInputNode("vid.mkv", nil, nil)
+		mkv.Metadata = &ProbeResult{Streams: []ProbeStream{
+			{Index: 0, CodecType: "video"}, {Index: 1, CodecType: "subtitle"}, {Index: 2, CodecType: "subtitle"}, {Index: 3, CodecType: "subtitle"},
+		}}
+		require.NoError(t, exec.AddSubtitleTrack(mkv, SubtitleStreams(), WebVTT))
+		require.NoError(t, exec.AddSubtitleTrack(mkv, SubtitleStreams().WithLanguage("ger").AsOptional(), ASS))
+		require.NoError(t, exec.AddSubtitleTrack(subs, StreamAt(0), SRT))
+		exec.SetStreamMetadata(SubtitleStreams().Nth(0), "language", "eng")
+
+		cmd := exec.ToFfmpeg()
+		require.Equal(t, FfmpegCommand{
+			"-i", "vid.mp4", "-i", "subs.srt", "-i", "vid.mkv",
+			"-map", "0:v", "-map", "0:a", "-map", "1:s:0", "-map", "2:s", "-map", "2:s:m:language:ger?", "-map", "1:0",
+			"-c:s:0", "mov_text", "-c:s:1", "webvtt", "-c:s:2", "webvtt", "-c:s:3", "webvtt", "-c:s:4", "srt",
+			"-metadata:s:s:0", "language=eng",
+			"-c:v", "copy", "-c:a", "copy", "out.mp4",
+		}, cmd)
+	})
+
+	t.Run("cues", func(t *testing.T) {
+		cues := []Cue{
+			{Start: 1500 * time.Millisecond, End: 3 * time.Second, Text: "Hello\n\nworld"},
+			{Start: time.Hour + 2*time.Minute, End: time.Hour + 2*time.Minute + 1, Text: "<b> & </b>"},
+		}
+
+		require.Equal(t, "1\n00:00:01,500 --> 00:00:03,000\nHello\nworld\n\n2\n01:02:00,000 --> 01:02:00,000\n<b> & </b>\n\n", SRTCues(cues))
+		require.Equal(t, "WEBVTT\n\n00:00:01.500 --> 00:00:03.000\nHello\nworld\n\n01:02:00.000 --> 01:02:00.000\n&lt;b&gt; &amp; &lt;/b&gt;\n\n", WebVTTCues(cues))
+	})
+}
@@ -0,0 +1,147 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FileOutput implements IOutput
+var _ IOutput = &FileOutput{}
+
+// FileOutput is a plain output file. Format is guessed from the name by ffmpeg if it is empty.
+type FileOutput struct {
+	Name   string
+	Format string
+}
+
+func (f *FileOutput) ToString() []string {
+	if f.Format == "" {
+		return []string{f.Name}
+	}
+
+	return []string{"-f", f.Format, f.Name}
+}
+
+// TeeOutput implements IOutput
+var _ IOutput = &TeeOutput{}
+
+// TeeSlave is a destination of a TeeOutput. Muxer options of Output, such as '-f' or '-hls_time', become options of
+// the slave.
+type TeeSlave struct {
+	Output IOutput
+
+	// Select restricts streams written to the slave. Specifiers refer to output streams, not to input streams.
+	Select []StreamSpecifier
+
+	// OnFail is either 'abort', which is the default, or 'ignore' to keep writing other slaves if this one fails.
+	OnFail string
+
+	// Options are other slave options such as 'bsfs' or 'use_fifo'.
+	Options map[string]string
+}
+
+// TeeOutput writes the same encoded streams to several destinations with '-f tee', so that streams are encoded once.
+type TeeOutput struct {
+	Slaves []TeeSlave
+}
+
+// NewTeeOutput returns a tee output writing to slaves.
+func NewTeeOutput(slaves ...TeeSlave) (*TeeOutput, error) {
+	if len(slaves) == 0 {
+		return nil, fmt.Errorf("tee output needs at least one slave")
+	}
+
+	for i, s := range slaves {
+		if s.Output == nil {
+			return nil, fmt.Errorf("slave %v has no output", i)
+		}
+		if s.OnFail != "" && s.OnFail != "abort" && s.OnFail != "ignore" {
+			return nil, fmt.Errorf("unknown onfail value: %v", s.OnFail)
+		}
+		if _, _, err := slaveOptions(s.Output); err != nil {
+			return nil, fmt.Errorf("slave %v: %w", i, err)
+		}
+		for _, spec := range s.Select {
+			if err := spec.check(); err != nil {
+				return nil, fmt.Errorf("slave %v: %w", i, err)
+			}
+		}
+	}
+
+	return &TeeOutput{Slaves: slaves}, nil
+}
+
+func (t *TeeOutput) ToString() []string {
+	slaves := make([]string, 0, len(t.Slaves))
+	for _, s := range t.Slaves {
+		slaves = append(slaves, s.String())
+	}
+
+	return []string{"-f", "tee", strings.Join(slaves, "|")}
+}
+
+// String returns the slave in the syntax of tee muxer, such as '[f=mpegts:onfail=ignore]udp://10.0.1.255:1234/'.
+func (s TeeSlave) String() string {
+	opts, name, _ := slaveOptions(s.Output)
+	if len(s.Select) > 0 {
+		specs := make([]string, 0, len(s.Select))
+		for _, spec := range s.Select {
+			specs = append(specs, spec.String())
+		}
+		opts = append(opts, [2]string{"select", strings.Join(specs, ",")})
+	}
+	if s.OnFail != "" {
+		opts = append(opts, [2]string{"onfail", s.OnFail})
+	}
+
+	keys := make([]string, 0, len(s.Options))
+	for k := range s.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		opts = append(opts, [2]string{k, s.Options[k]})
+	}
+
+	// escaped brackets are unescaped before a slave is parsed, hence a name starting with '[' would be read as options
+	// unless they are given, even if empty
+	if len(opts) == 0 && !strings.HasPrefix(name, "[") {
+		return escapeTeeSlave(name)
+	}
+
+	parts := make([]string, 0, len(opts))
+	for _, o := range opts {
+		parts = append(parts, o[0]+"="+escapeTeeOption(o[1]))
+	}
+
+	return "[" + strings.Join(parts, ":") + "]" + escapeTeeSlave(name)
+}
+
+// slaveOptions splits arguments of an output into its options, which are in the form of '-key value', and its name.
+func slaveOptions(o IOutput) (opts [][2]string, name string, err error) {
+	args := o.ToString()
+	if len(args)%2 != 1 {
+		return nil, "", fmt.Errorf("output arguments should be option pairs followed by a name: %v", args)
+	}
+
+	for i := 0; i < len(args)-1; i += 2 {
+		if !strings.HasPrefix(args[i], "-") {
+			return nil, "", fmt.Errorf("output option should start with '-': %v", args[i])
+		}
+		opts = append(opts, [2]string{strings.TrimPrefix(args[i], "-"), args[i+1]})
+	}
+
+	return opts, args[len(args)-1], nil
+}
+
+// escapeTeeSlave escapes characters which separate slaves or end their options.
+func escapeTeeSlave(v string) string {
+	return strings.NewReplacer("\\", "\\\\", "'", "\\'", "|", "\\|", "[", "\\[", "]", "\\]").Replace(v)
+}
+
+// escapeTeeOption escapes a value of a slave option, which ends at ':' or ']'. It is escaped once for the option parser
+// and once more for the slave parser, like filter options.
+func escapeTeeOption(v string) string {
+	return escapeTeeSlave(strings.NewReplacer("\\", "\\\\", "'", "\\'", ":", "\\:", "=", "\\=", "[", "\\[", "]", "\\]").Replace(v))
+}
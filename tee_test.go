@@ -0,0 +1,49 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeeOutput(t *testing.T) {
+	i := NewInputNode("vid.mp4", nil, nil)
+	scaled := NewScaleFilterNode(i, 1280, 720, false)
+
+	tee, err := NewTeeOutput(
+		TeeSlave{Output: &FileOutput{Name: "out|1.mp4"}},
+		TeeSlave{Output: &FileOutput{Name: "archive/[raw].mkv", Format: "matroska"}, OnFail: "ignore"},
+		TeeSlave{
+			Output: &HLSOutput{PlaylistName: "live.m3u8", SegmentDuration: 4 * time.Second},
+			Select: []StreamSpecifier{VideoStreams().Nth(0)},
+			OnFail: "ignore",
+		},
+	)
+	require.NoError(t, err)
+
+	exec := NewFfmpegExecutor(nil, "", []string{"-c:v", "libx264"})
+	exec.SetOutput(tee)
+	cmd := exec.ToFfmpeg(scaled)
+
+	require.Equal(t, "[0:0]scale=1280:720["+scaled.GetOutStreamName()+"]", cmd.FilterComplex())
+	require.Equal(t, []string{
+		"-map", "[" + scaled.GetOutStreamName() + "]", "-c:v", "libx264", "-f", "tee",
+		`out\|1.mp4|[f=matroska:onfail=ignore]archive/\[raw\].mkv|[f=hls:hls_time=4:select=v\\:0:onfail=ignore]live.m3u8`,
+	}, []string(cmd[4:]))
+
+	// option values end at ']', hence brackets in them are escaped in both levels
+	tee, err = NewTeeOutput(TeeSlave{Output: &FileOutput{Name: "out.ts"}, Options: map[string]string{"metadata": "title=[draft]"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"-f", "tee", `[metadata=title\\=\\\[draft\\\]]out.ts`}, tee.ToString())
+
+	// a name starting with a bracket is preceded by empty options so that it is not read as options
+	tee, err = NewTeeOutput(TeeSlave{Output: &FileOutput{Name: "[raw].mkv"}}, TeeSlave{Output: &FileOutput{Name: "out.mp4"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"-f", "tee", `[]\[raw\].mkv|out.mp4`}, tee.ToString())
+
+	_, err = NewTeeOutput(TeeSlave{Output: &FileOutput{Name: "out.mp4"}, OnFail: "retry"})
+	require.Error(t, err)
+	_, err = NewTeeOutput()
+	require.Error(t, err)
+}
@@ -0,0 +1,256 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+// FontMetrics measures text with a font, so that it can be laid out before it is drawn by drawtext with the same font.
+type FontMetrics struct {
+	font *opentype.Font
+}
+
+// LoadFontMetrics reads a TrueType or OpenType font file.
+func LoadFontMetrics(path string) (*FontMetrics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read font: %w", err)
+	}
+
+	return ParseFontMetrics(data)
+}
+
+func ParseFontMetrics(data []byte) (*FontMetrics, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font: %w", err)
+	}
+
+	return &FontMetrics{font: f}, nil
+}
+
+// face returns the font at size in pixels, which is what fontsize of drawtext is.
+func (m *FontMetrics) face(size int) (font.Face, error) {
+	return opentype.NewFace(m.font, &opentype.FaceOptions{Size: float64(size), DPI: 72, Hinting: font.HintingFull})
+}
+
+// Width returns the width of text in pixels.
+func (m *FontMetrics) Width(text string, size int) (int, error) {
+	face, err := m.face(size)
+	if err != nil {
+		return 0, err
+	}
+	defer face.Close()
+
+	return font.MeasureString(face, text).Ceil(), nil
+}
+
+// LineHeight returns the recommended distance between baselines of consecutive lines in pixels.
+func (m *FontMetrics) LineHeight(size int) (int, error) {
+	face, err := m.face(size)
+	if err != nil {
+		return 0, err
+	}
+	defer face.Close()
+
+	return face.Metrics().Height.Ceil(), nil
+}
+
+// Ascent returns the distance from the top of a line to its baseline in pixels.
+func (m *FontMetrics) Ascent(size int) (int, error) {
+	face, err := m.face(size)
+	if err != nil {
+		return 0, err
+	}
+	defer face.Close()
+
+	return face.Metrics().Ascent.Ceil(), nil
+}
+
+// WrapText breaks text into lines which are not wider than maxWidth at size. Lines are broken between words, and words
+// which do not fit in a line on their own are broken between characters. Line breaks in text are kept.
+func WrapText(m *FontMetrics, text string, size, maxWidth int) ([]string, error) {
+	face, err := m.face(size)
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	fits := func(s string) bool {
+		return font.MeasureString(face, s).Ceil() <= maxWidth
+	}
+
+	lines := make([]string, 0)
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && fits(line+" "+word) {
+				line += " " + word
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			// a long word fills as many lines as it needs and its rest starts the next line
+			line = ""
+			for _, r := range word {
+				if line != "" && !fits(line+string(r)) {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+)
+
+type VerticalAnchor int
+
+const (
+	AnchorTop VerticalAnchor = iota
+	AnchorMiddle
+	AnchorBottom
+)
+
+// TextLayout draws text wrapped in a box of Width pixels starting from X, since drawtext does not wrap text.
+type TextLayout struct {
+	Metrics *FontMetrics
+
+	// FontFile is the font drawtext uses, which should be the one Metrics are loaded from.
+	FontFile  string
+	FontSize  int
+	FontColor string
+
+	X, Width int
+	Align    TextAlign
+
+	// Y is the top, middle or bottom of the text, depending on Anchor.
+	Y      int
+	Anchor VerticalAnchor
+
+	// LineSpacing is the extra space between lines in pixels.
+	LineSpacing int
+
+	// Text is shown from Start until End, or until the end if End is zero. Every line is shown LineDelay later than
+	// the previous one.
+	Start, End, LineDelay time.Duration
+}
+
+// Lines returns text broken into lines which fit in the box.
+func (l *TextLayout) Lines(text string) ([]string, error) {
+	if l.Metrics == nil || l.FontSize <= 0 || l.Width <= 0 {
+		return nil, fmt.Errorf("font metrics, font size and width should be set")
+	}
+
+	return WrapText(l.Metrics, text, l.FontSize, l.Width)
+}
+
+// Draw draws every line of text with a separate drawtext filter, which are chained after input.
+func (l *TextLayout) Draw(input INode, text string) (INode, error) {
+	lines, err := l.Lines(text)
+	if err != nil {
+		return nil, err
+	}
+	lineHeight, err := l.Metrics.LineHeight(l.FontSize)
+	if err != nil {
+		return nil, err
+	}
+	ascent, err := l.Metrics.Ascent(l.FontSize)
+	if err != nil {
+		return nil, err
+	}
+
+	step := lineHeight + l.LineSpacing
+	top := l.Y
+	switch l.Anchor {
+	case AnchorMiddle:
+		top -= (len(lines)*step - l.LineSpacing) / 2
+	case AnchorBottom:
+		top -= len(lines)*step - l.LineSpacing
+	}
+
+	// drawtext places the top of the glyphs of a line at y, which depends on the glyphs, hence every line is placed by
+	// its baseline using the ascent drawtext measures for it
+	res := input
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		y := fmt.Sprintf("%v-ascent", top+ascent+i*step)
+		f := NewDrawTextFilter(res, line, l.FontColor, l.alignedX(), y, 0, l.FontSize)
+		f.FontFile = l.FontFile
+		l.setTiming(&f.TimelineAcceptingFilterNode, l.Start+time.Duration(i)*l.LineDelay)
+		res = f
+	}
+
+	return res, nil
+}
+
+// DrawMultiline draws all lines with a single drawtext filter, see TextAlign of DrawTextFilter for aligning lines
+// other than to left. LineDelay is not used.
+func (l *TextLayout) DrawMultiline(input INode, text string) (*DrawTextFilter, error) {
+	lines, err := l.Lines(text)
+	if err != nil {
+		return nil, err
+	}
+
+	y := strconv.Itoa(l.Y)
+	switch l.Anchor {
+	case AnchorMiddle:
+		y += "-text_h/2"
+	case AnchorBottom:
+		y += "-text_h"
+	}
+
+	f := NewDrawTextFilter(input, strings.Join(lines, "\n"), l.FontColor, l.alignedX(), y, 0, l.FontSize)
+	f.FontFile = l.FontFile
+	f.LineSpacing = l.LineSpacing
+	switch l.Align {
+	case AlignCenter:
+		f.TextAlign = "center"
+	case AlignRight:
+		f.TextAlign = "right"
+	}
+	l.setTiming(&f.TimelineAcceptingFilterNode, l.Start)
+
+	return f, nil
+}
+
+// alignedX returns x of drawtext, which aligns the text in the box using its width.
+func (l *TextLayout) alignedX() string {
+	switch l.Align {
+	case AlignCenter:
+		return fmt.Sprintf("%v+(%v-text_w)/2", l.X, l.Width)
+	case AlignRight:
+		return fmt.Sprintf("%v+%v-text_w", l.X, l.Width)
+	}
+
+	return strconv.Itoa(l.X)
+}
+
+func (l *TextLayout) setTiming(n *TimelineAcceptingFilterNode, start time.Duration) {
+	if l.End > 0 {
+		n.Window(start, l.End)
+	} else if start > 0 {
+		n.Since(start.Seconds())
+	}
+}
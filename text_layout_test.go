@@ -0,0 +1,183 @@
+package ffmpegtree
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func testFontMetrics(t *testing.T) *FontMetrics {
+	m, err := ParseFontMetrics(goregular.TTF)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestWrapText(t *testing.T) {
+	m := testFontMetrics(t)
+	text := "the quick brown fox jumps over the lazy dog"
+
+	lines, err := WrapText(m, text, 40, 300)
+	require.NoError(t, err)
+	require.Greater(t, len(lines), 1)
+	require.Equal(t, text, strings.Join(lines, " "))
+	for i, line := range lines {
+		w, err := m.Width(line, 40)
+		require.NoError(t, err)
+		require.LessOrEqual(t, w, 300)
+
+		// a line is broken only if the next word does not fit in it
+		if i+1 < len(lines) {
+			next := strings.Fields(lines[i+1])[0]
+			w, err = m.Width(line+" "+next, 40)
+			require.NoError(t, err)
+			require.Greater(t, w, 300)
+		}
+	}
+
+	lines, err = WrapText(m, text, 40, 10000)
+	require.NoError(t, err)
+	require.Equal(t, []string{text}, lines)
+
+	// line breaks are kept, and empty lines too
+	lines, err = WrapText(m, "first\n\nsecond", 40, 10000)
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "", "second"}, lines)
+
+	// words longer than a line are broken between characters
+	lines, err = WrapText(m, "a supercalifragilisticexpialidocious word", 40, 150)
+	require.NoError(t, err)
+	require.Greater(t, len(lines), 2)
+	require.Equal(t, "a", lines[0])
+	require.Equal(t, "asupercalifragilisticexpialidociousword", strings.ReplaceAll(strings.Join(lines, ""), " ", ""))
+	for _, line := range lines {
+		w, err := m.Width(line, 40)
+		require.NoError(t, err)
+		require.LessOrEqual(t, w, 150)
+	}
+}
+
+func TestTextLayoutDraw(t *testing.T) {
+	m := testFontMetrics(t)
+	lineHeight, err := m.LineHeight(40)
+	require.NoError(t, err)
+	ascent, err := m.Ascent(40)
+	require.NoError(t, err)
+
+	l := &TextLayout{
+		Metrics:     m,
+		FontFile:    "/fonts/Go-Regular.ttf",
+		FontSize:    40,
+		FontColor:   "white",
+		X:           100,
+		Width:       300,
+		Align:       AlignCenter,
+		Y:           1000,
+		Anchor:      AnchorBottom,
+		LineSpacing: 10,
+		Start:       time.Second,
+		End:         5 * time.Second,
+		LineDelay:   500 * time.Millisecond,
+	}
+	text := "the quick brown fox jumps over the lazy dog"
+	lines, err := l.Lines(text)
+	require.NoError(t, err)
+
+	res, err := l.Draw(NewInputNode("vid.mp4", nil, nil), text)
+	require.NoError(t, err)
+
+	// filters are chained, so the last line is the last filter
+	top := 1000 - len(lines)*(lineHeight+10) + 10
+	node := res
+	for i := len(lines) - 1; i >= 0; i-- {
+		f, ok := node.(*DrawTextFilter)
+		require.True(t, ok)
+		require.Equal(t, lines[i], f.text)
+		require.Equal(t, "100+(300-text_w)/2", f.x)
+		require.Equal(t, strconv.Itoa(top+ascent+i*(lineHeight+10))+"-ascent", f.y)
+		require.Equal(t, "/fonts/Go-Regular.ttf", f.FontFile)
+		require.Equal(t, "between(t,"+fmtSeconds(time.Second+time.Duration(i)*500*time.Millisecond)+",5)", f.EnableExpr())
+		node = f.GetInputs()[0]
+	}
+	_, ok := node.(*InputNode)
+	require.True(t, ok)
+
+	l.Align, l.Anchor, l.End, l.LineDelay = AlignRight, AnchorTop, 0, 0
+	res, err = l.Draw(NewInputNode("vid.mp4", nil, nil), "hello")
+	require.NoError(t, err)
+	f := res.(*DrawTextFilter)
+	require.Equal(t, "100+300-text_w", f.x)
+	require.Equal(t, strconv.Itoa(1000+ascent)+"-ascent", f.y)
+	require.Equal(t, "gte(t,1)", f.EnableExpr())
+
+	_, err = (&TextLayout{FontSize: 40, Width: 300}).Draw(NewInputNode("vid.mp4", nil, nil), "hello")
+	require.Error(t, err)
+}
+
+func TestTextLayoutBaselines(t *testing.T) {
+	m := testFontMetrics(t)
+	face, err := m.face(40)
+	require.NoError(t, err)
+	defer face.Close()
+	lineHeight, err := m.LineHeight(40)
+	require.NoError(t, err)
+	ascent, err := m.Ascent(40)
+	require.NoError(t, err)
+
+	l := &TextLayout{Metrics: m, FontSize: 40, Width: 1000, Y: 100, LineSpacing: 10}
+	res, err := l.Draw(NewInputNode("vid.mp4", nil, nil), "ace\nTg\nmmm")
+	require.NoError(t, err)
+
+	// drawtext puts the top of the glyphs of a line at y, where ascent is the highest point of them above the baseline
+	baselines, ascents := make([]int, 0), make(map[int]bool)
+	for node := res; ; node = node.GetInputs()[0] {
+		f, ok := node.(*DrawTextFilter)
+		if !ok {
+			break
+		}
+
+		bounds, _ := font.BoundString(face, f.text)
+		glyphAscent := (-bounds.Min.Y).Ceil()
+		ascents[glyphAscent] = true
+
+		y := regexp.MustCompile(`:y=(\d+)(-ascent)?`).FindStringSubmatch(f.FilterString())
+		require.NotNil(t, y)
+		top, err := strconv.Atoi(y[1])
+		require.NoError(t, err)
+		if y[2] != "" {
+			top -= glyphAscent
+		}
+
+		baselines = append([]int{top + glyphAscent}, baselines...)
+	}
+
+	// lines are evenly spaced whatever their glyphs are
+	require.Greater(t, len(ascents), 1)
+	require.Equal(t, []int{100 + ascent, 100 + ascent + lineHeight + 10, 100 + ascent + 2*(lineHeight+10)}, baselines)
+}
+
+func TestTextLayoutDrawMultiline(t *testing.T) {
+	l := &TextLayout{
+		Metrics:     testFontMetrics(t),
+		FontSize:    40,
+		FontColor:   "white",
+		X:           100,
+		Width:       300,
+		Align:       AlignCenter,
+		Y:           540,
+		Anchor:      AnchorMiddle,
+		LineSpacing: 10,
+	}
+
+	f, err := l.DrawMultiline(NewInputNode("vid.mp4", nil, nil), "the quick brown fox")
+	require.NoError(t, err)
+	require.Equal(t,
+		"drawtext=expansion=none:text=''the quick brown\nfox'':fontcolor=white:fontsize=40:x=100+(300-text_w)/2:y=540-text_h/2:line_spacing=10:text_align=center",
+		f.FilterString())
+}
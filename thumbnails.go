@@ -0,0 +1,236 @@
+package ffmpegtree
+
+import (
+	"fmt"
+	"time"
+)
+
+// SelectFilter passes only frames for which Expr is not zero.
+type SelectFilter struct {
+	BaseFilterNode
+	Expr Expr
+}
+
+func (f *SelectFilter) FilterString() string {
+	return "select=" + Quoted(f.Expr)
+}
+
+func NewSelectFilter(input INode, expr Expr) *SelectFilter {
+	return &SelectFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		Expr:           expr,
+	}
+}
+
+// ThumbnailFilter picks the most representative frame in every batch of N frames.
+type ThumbnailFilter struct {
+	BaseFilterNode
+	N int
+}
+
+func (f *ThumbnailFilter) FilterString() string {
+	if f.N <= 0 {
+		return "thumbnail"
+	}
+
+	return fmt.Sprintf("thumbnail=n=%v", f.N)
+}
+
+func NewThumbnailFilter(input INode, n int) *ThumbnailFilter {
+	return &ThumbnailFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		N:              n,
+	}
+}
+
+// TileFilter lays out consecutive frames in a grid of Columns x Rows, producing one frame for every grid.
+type TileFilter struct {
+	BaseFilterNode
+	Columns, Rows int
+
+	// Padding is the space between tiles and Margin is the space around the grid, in pixels.
+	Padding, Margin int
+
+	// Color fills the padding and margin, black if empty.
+	Color string
+}
+
+func (f *TileFilter) FilterString() string {
+	opts := []string{fmt.Sprintf("%vx%v", f.Columns, f.Rows)}
+	if f.Padding > 0 {
+		opts = append(opts, fmt.Sprintf("padding=%v", f.Padding))
+	}
+	if f.Margin > 0 {
+		opts = append(opts, fmt.Sprintf("margin=%v", f.Margin))
+	}
+	if f.Color != "" {
+		opts = append(opts, "color="+f.Color)
+	}
+
+	return filterWithOptions("tile", opts)
+}
+
+func NewTileFilter(input INode, columns, rows int) *TileFilter {
+	return &TileFilter{
+		BaseFilterNode: *NewBaseFilterNode([]INode{input}, randStr()),
+		Columns:        columns,
+		Rows:           rows,
+	}
+}
+
+func (f *SelectFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	return StreamInfo{Width: inputs[0].Width, Height: inputs[0].Height, SAR: inputs[0].SAR}
+}
+
+func (f *ThumbnailFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	return StreamInfo{Width: inputs[0].Width, Height: inputs[0].Height, SAR: inputs[0].SAR}
+}
+
+func (f *TileFilter) PropagateInfo(inputs []StreamInfo) StreamInfo {
+	res := StreamInfo{SAR: inputs[0].SAR}
+	if inputs[0].Width != nil && inputs[0].Height != nil {
+		res.Width = intPtr(*inputs[0].Width*f.Columns + f.Padding*(f.Columns-1) + 2*f.Margin)
+		res.Height = intPtr(*inputs[0].Height*f.Rows + f.Padding*(f.Rows-1) + 2*f.Margin)
+	}
+
+	return res
+}
+
+// ImageOutput implements IOutput
+var _ IOutput = &ImageOutput{}
+
+// ImageOutput writes frames as images with image2 muxer. Name is a pattern such as 'thumb_%03d.jpg' if more than one
+// frame is written.
+type ImageOutput struct {
+	Name string
+
+	// Frames limits the number of written frames if it is positive.
+	Frames int
+
+	// Quality is the jpeg quality between 2 and 31, lower is better.
+	Quality *int
+}
+
+func (o *ImageOutput) ToString() []string {
+	res := make([]string, 0)
+	if o.Frames > 0 {
+		res = append(res, "-frames:v", fmt.Sprintf("%v", o.Frames))
+	}
+	if o.Quality != nil {
+		res = append(res, "-q:v", fmt.Sprintf("%v", *o.Quality))
+	}
+
+	// selected frames keep their timestamps, which would otherwise be filled with duplicated frames
+	return append(res, "-fps_mode", "vfr", "-f", "image2", o.Name)
+}
+
+// ThumbnailAt returns the first frame at or after at. Decoding starts from the beginning, hence Offset of the input
+// node should be preferred for a single thumbnail of a long video.
+func ThumbnailAt(node INode, at time.Duration, name string) (INode, *ImageOutput) {
+	return NewSelectFilter(node, Gte(T(), Num(at.Seconds()))), &ImageOutput{Name: name, Frames: 1}
+}
+
+// EvenlySpacedThumbnails returns count frames, one from the middle of every equal part of the video. Duration of node
+// should be known, see Info.
+func EvenlySpacedThumbnails(node INode, count int, pattern string) (INode, *ImageOutput, error) {
+	interval, err := thumbnailInterval(node, count)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// selected_n is the number of frames selected so far
+	expr := Gte(T(), Mul(Num(interval.Seconds()), Add(Var("selected_n"), Num(0.5))))
+
+	return NewSelectFilter(node, expr), &ImageOutput{Name: pattern, Frames: count}, nil
+}
+
+// RepresentativeThumbnail returns the most representative frame among the first batch frames, which is a better poster
+// frame than an arbitrary one.
+func RepresentativeThumbnail(node INode, batch int, name string) (INode, *ImageOutput) {
+	return NewThumbnailFilter(node, batch), &ImageOutput{Name: name, Frames: 1}
+}
+
+// ContactSheet is a grid of evenly spaced frames of a video, such as a sprite sheet for scrubbing previews.
+type ContactSheet struct {
+	Columns, Rows int
+
+	// TileWidth is the width of every tile. Height keeps the aspect ratio.
+	TileWidth int
+
+	Padding, Margin int
+	Color           string
+
+	// Timestamps draws the time of every tile on its bottom left corner.
+	Timestamps bool
+	FontSize   int
+	FontColor  string
+}
+
+// Build returns the sheet, which should be written with an ImageOutput of one frame.
+func (c *ContactSheet) Build(node INode) (INode, error) {
+	if c.Columns <= 0 || c.Rows <= 0 || c.TileWidth <= 0 {
+		return nil, fmt.Errorf("grid and tile width should be positive")
+	}
+
+	selected, _, err := EvenlySpacedThumbnails(node, c.Columns*c.Rows, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var tile INode = NewScaleFilterNode(selected, c.TileWidth, -1, false)
+	if c.Timestamps {
+		fontSize := c.FontSize
+		if fontSize == 0 {
+			fontSize = 16
+		}
+		tile = NewTimestampFilter(tile, c.FontColor, "4", "h-text_h-4", fontSize)
+	}
+
+	res := NewTileFilter(tile, c.Columns, c.Rows)
+	res.Padding, res.Margin, res.Color = c.Padding, c.Margin, c.Color
+
+	return res, nil
+}
+
+// SpriteCues returns WebVTT cues which point each part of the video to its tile in image, in the form of
+// 'sheet.jpg#xywh=0,0,160,90'. They can be written with WriteSubtitles. Size of node should be known, see Info.
+func (c *ContactSheet) SpriteCues(node INode, image string) ([]Cue, error) {
+	count := c.Columns * c.Rows
+	interval, err := thumbnailInterval(node, count)
+	if err != nil {
+		return nil, err
+	}
+
+	info := Info(node)
+	w, h, ok := scaledSize(c.TileWidth, -1, info.Width, info.Height)
+	if !ok {
+		return nil, fmt.Errorf("size of video is not known")
+	}
+
+	cues := make([]Cue, 0, count)
+	for i := 0; i < count; i++ {
+		x := c.Margin + (i%c.Columns)*(w+c.Padding)
+		y := c.Margin + (i/c.Columns)*(h+c.Padding)
+		cues = append(cues, Cue{
+			Start: time.Duration(i) * interval,
+			End:   time.Duration(i+1) * interval,
+			Text:  fmt.Sprintf("%v#xywh=%v,%v,%v,%v", image, x, y, w, h),
+		})
+	}
+
+	return cues, nil
+}
+
+// thumbnailInterval divides duration of node into count parts.
+func thumbnailInterval(node INode, count int) (time.Duration, error) {
+	if count <= 0 {
+		return 0, fmt.Errorf("thumbnail count should be positive")
+	}
+
+	d := Info(node).Duration
+	if d == nil || *d <= 0 {
+		return 0, fmt.Errorf("duration of video is not known")
+	}
+
+	return *d / time.Duration(count), nil
+}
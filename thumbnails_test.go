@@ -0,0 +1,55 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnails(t *testing.T) {
+	t.Run("single thumbnail", func(t *testing.T) {
+		i := NewInputNode("vid.mp4", nil, nil)
+		thumb, out := ThumbnailAt(i, 3*time.Second, "poster.jpg")
+
+		exec := NewFfmpegExecutor(nil, "", nil)
+		exec.SetOutput(out)
+		cmd := exec.ToFfmpeg(thumb)
+		require.Equal(t, []string{"-i", "vid.mp4", "-filter_complex", "[0:0]select='gte(t,3)'", "-frames:v", "1", "-fps_mode", "vfr", "-f", "image2", "poster.jpg"}, []string(cmd))
+	})
+
+	t.Run("evenly spaced thumbnails", func(t *testing.T) {
+		thumbs, out, err := EvenlySpacedThumbnails(probedInput(t), 4, "thumb_%02d.jpg")
+		require.NoError(t, err)
+		require.Equal(t, "select='gte(t,2.5*(selected_n+0.5))'", thumbs.(IFilterNode).FilterString())
+		require.Equal(t, 4, out.Frames)
+
+		_, _, err = EvenlySpacedThumbnails(NewInputNode("vid.mp4", nil, nil), 4, "thumb_%02d.jpg")
+		require.Error(t, err)
+	})
+
+	t.Run("representative thumbnail", func(t *testing.T) {
+		thumb, _ := RepresentativeThumbnail(NewInputNode("vid.mp4", nil, nil), 100, "poster.jpg")
+		require.Equal(t, "thumbnail=n=100", thumb.(IFilterNode).FilterString())
+	})
+}
+
+func TestContactSheet(t *testing.T) {
+	i := probedInput(t)
+	sheet := &ContactSheet{Columns: 4, Rows: 2, TileWidth: 160, Padding: 2, Margin: 4, Timestamps: true, FontColor: "white"}
+
+	node, err := sheet.Build(i)
+	require.NoError(t, err)
+	cmd := Select([]INode{node}, "sheet.jpg", []string{"-frames:v", "1"})
+	require.Equal(t, `[0:0]select='gte(t,1.25*(selected_n+0.5))',scale=160:-1,drawtext=text='%{pts\:hms}':fontcolor=white:fontsize=16:x=4:y=h-text_h-4,tile=4x2:padding=2:margin=4`, cmd.FilterComplex())
+
+	info := Info(node)
+	require.Equal(t, 4*160+3*2+2*4, *info.Width)
+	require.Equal(t, 2*90+2+2*4, *info.Height)
+
+	cues, err := sheet.SpriteCues(i, "sheet.jpg")
+	require.NoError(t, err)
+	require.Len(t, cues, 8)
+	require.Equal(t, Cue{Start: 0, End: 1250 * time.Millisecond, Text: "sheet.jpg#xywh=4,4,160,90"}, cues[0])
+	require.Equal(t, Cue{Start: 6250 * time.Millisecond, End: 7500 * time.Millisecond, Text: "sheet.jpg#xywh=166,96,160,90"}, cues[5])
+}
@@ -1,17 +1,29 @@
 package ffmpegtree
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type TimelineAcceptingFilterNode struct {
 	BaseFilterNode
 	enableExpr   string
 	since, until *float64
+	windows      []string
 }
 
+// Enable sets a custom enable expression. It is combined with windows so that the filter is enabled only when the
+// expression is true inside any of the windows.
 func (n *TimelineAcceptingFilterNode) Enable(exp string) {
 	n.enableExpr = exp
 }
 
+// EnableWhen is the same as Enable but takes an Expr.
+func (n *TimelineAcceptingFilterNode) EnableWhen(e Expr) {
+	n.enableExpr = e.String()
+}
+
 func (n *TimelineAcceptingFilterNode) Since(since float64) {
 	n.since = &since
 }
@@ -20,25 +32,54 @@ func (n *TimelineAcceptingFilterNode) Until(until float64) {
 	n.until = &until
 }
 
+// Window enables the filter between from and to. It can be called more than once to enable the filter in several
+// windows.
+func (n *TimelineAcceptingFilterNode) Window(from, to time.Duration) {
+	n.windows = append(n.windows, fmt.Sprintf("between(t,%v,%v)", fmtSeconds(from), fmtSeconds(to)))
+}
+
+// FrameWindow enables the filter between given frame numbers, both inclusive.
+func (n *TimelineAcceptingFilterNode) FrameWindow(from, to int) {
+	n.windows = append(n.windows, fmt.Sprintf("between(n,%v,%v)", from, to))
+}
+
 func (n *TimelineAcceptingFilterNode) EnableExpr() string {
-	if n.enableExpr != "" {
+	windows := make([]string, 0, len(n.windows)+1)
+	if w := n.sinceUntilExpr(); w != "" {
+		windows = append(windows, w)
+	}
+	windows = append(windows, n.windows...)
+
+	// a frame is in the union of the windows if the sum of them is not zero
+	union := strings.Join(windows, "+")
+	if n.enableExpr == "" {
+		return union
+	}
+	if union == "" {
 		return n.enableExpr
 	}
+	if len(windows) > 1 {
+		union = "(" + union + ")"
+	}
+
+	return fmt.Sprintf("(%v)*%v", n.enableExpr, union)
+}
 
-	if n.enableExpr == "" && n.since == nil && n.until == nil {
+func (n *TimelineAcceptingFilterNode) sinceUntilExpr() string {
+	if n.since == nil && n.until == nil {
 		return ""
 	}
 
 	if n.since != nil && n.until != nil {
-		return fmt.Sprintf("between(t, %.2f, %.2f)", *n.since, *n.until)
+		return fmt.Sprintf("between(t,%v,%v)", fmtFloat(*n.since), fmtFloat(*n.until))
 	}
 
 	if n.since != nil {
-		return fmt.Sprintf("gte(t, %.2f)", *n.since)
+		return fmt.Sprintf("gte(t,%v)", fmtFloat(*n.since))
 	}
 
 	// if only until is set
-	return fmt.Sprintf("lte(t, %.2f)", *n.until)
+	return fmt.Sprintf("lte(t,%v)", fmtFloat(*n.until))
 }
 
 func NewTimelineAcceptingFilterNode(children []INode, outStreamName string) *TimelineAcceptingFilterNode {
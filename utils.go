@@ -2,20 +2,37 @@ package ffmpegtree
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// fmtDuration formats a duration as 'HH:MM:SS', adding milliseconds only if there is a fraction of a second.
 func fmtDuration(d time.Duration) string {
-	d = d.Round(time.Second)
+	d = d.Round(time.Millisecond)
 	h := d / time.Hour
 	d -= h * time.Hour
 	m := d / time.Minute
 	d -= m * time.Minute
 	s := d / time.Second
+	d -= s * time.Second
+	ms := d / time.Millisecond
+	if ms != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)
+	}
 	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
 }
 
+// fmtSeconds formats a duration as seconds to be used in filter options.
+func fmtSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// fmtFloat formats a number without trailing zeros to be used in filter options.
+func fmtFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
 func escapeText(t string) string {
 	t = strings.ReplaceAll(t, "\\", "\\\\")
 	t = strings.ReplaceAll(t, "\"", "\\\"")
@@ -25,6 +42,19 @@ func escapeText(t string) string {
 	return "'" + t + "'"
 }
 
+// escapeExpandedText escapes a drawtext template in which expansion sequences such as '%{pts\:hms}' are kept, unlike
+// escapeText which escapes every '%'.
+func escapeExpandedText(t string) string {
+	return strings.NewReplacer("\\", "\\\\", "'", "'\\\\\\''", ":", "\\:").Replace(t)
+}
+
+// escapeFilterArg escapes a value, such as a path, to be placed into a filter option. It is escaped once for the
+// option parser of the filter and once more for the filter graph parser.
+func escapeFilterArg(v string) string {
+	v = strings.NewReplacer("\\", "\\\\", "'", "\\'", ":", "\\:").Replace(v)
+	return strings.NewReplacer("\\", "\\\\", "'", "\\'", "[", "\\[", "]", "\\]", ",", "\\,", ";", "\\;").Replace(v)
+}
+
 var x = 0
 
 func randStr() string {
@@ -0,0 +1,22 @@
+package ffmpegtree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFmtDuration(t *testing.T) {
+	require.Equal(t, "00:00:00", fmtDuration(0))
+	require.Equal(t, "01:02:03", fmtDuration(time.Hour+2*time.Minute+3*time.Second))
+	require.Equal(t, "00:00:02.500", fmtDuration(2500*time.Millisecond))
+	require.Equal(t, "00:01:00.001", fmtDuration(time.Minute+time.Millisecond))
+
+	// fractions below a millisecond are rounded
+	require.Equal(t, "00:00:01", fmtDuration(time.Second+400*time.Microsecond))
+	require.Equal(t, "00:00:01.001", fmtDuration(time.Second+600*time.Microsecond))
+
+	offset, length := 1500*time.Millisecond, 10*time.Second
+	require.Equal(t, []string{"-t", "00:00:10", "-ss", "00:00:01.500", "-i", "vid.mp4"}, NewInputNode("vid.mp4", &length, &offset).ToString())
+}